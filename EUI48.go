@@ -3,6 +3,7 @@ package netaddr
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // EUI48 (Extended Unique Identifier 48-bit, or EUI-48) represents a 48-bit hardware address.
@@ -45,6 +46,35 @@ func (eui EUI48) Bytes() []byte {
 	}
 }
 
+// CiscoString returns the EUI48 in the dotted format used by Cisco devices, eg. aabb.ccdd.eeff
+func (eui EUI48) CiscoString() string {
+	return fmt.Sprintf("%04x.%04x.%04x", uint64(eui>>32&0xffff), uint64(eui>>16&0xffff), uint64(eui&0xffff))
+}
+
+// IsLocallyAdministered returns true if the U/L bit (0x02 of the first octet) is set,
+// indicating an address assigned locally rather than from a vendor OUI.
+func (eui EUI48) IsLocallyAdministered() bool {
+	return eui>>40&0x02 != 0
+}
+
+// IsMulticast returns true if the I/G bit (0x01 of the first octet) is set,
+// indicating a group rather than an individual address.
+func (eui EUI48) IsMulticast() bool {
+	return eui>>40&0x01 != 0
+}
+
+// LinkLocalAddr returns the fe80::/64 link-local IPv6 address which an interface
+// with this EUI48 would autoconfigure. The interface identifier is the modified
+// EUI-64 of this address (see EUI64.ToModifiedEUI64).
+func (eui EUI48) LinkLocalAddr() *IPv6 {
+	return NewIPv6(0xfe80000000000000, uint64(eui.ToEUI64().ToModifiedEUI64()))
+}
+
+// OUI returns the first three octets of the EUI48, the Organizationally Unique Identifier.
+func (eui EUI48) OUI() [3]byte {
+	return [3]byte{byte(eui >> 40), byte(eui >> 32), byte(eui >> 24)}
+}
+
 func (eui EUI48) String() string {
 	if eui == 0 {
 		return ""
@@ -53,6 +83,22 @@ func (eui EUI48) String() string {
 	return fmt.Sprintf("%02x-%02x-%02x-%02x-%02x-%02x", bites[0], bites[1], bites[2], bites[3], bites[4], bites[5])
 }
 
+// StringWithSep returns the EUI48 as lowercase hex octets delimited by sep,
+// eg. aa:bb:cc:dd:ee:ff for a sep of ":".
+func (eui EUI48) StringWithSep(sep string) string {
+	bites := eui.Bytes()
+	octets := make([]string, len(bites))
+	for i, b := range bites {
+		octets[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(octets, sep)
+}
+
+// StringWithSepUpper is like StringWithSep but uses uppercase hex, eg. AA:BB:CC:DD:EE:FF
+func (eui EUI48) StringWithSepUpper(sep string) string {
+	return strings.ToUpper(eui.StringWithSep(sep))
+}
+
 // ToEUI64 converts this EUI48 into an EUI64 by inserting 0xfffe between the OUI and EUI
 func (eui EUI48) ToEUI64() EUI64 {
 	eui48 := uint64(eui)
@@ -27,6 +27,25 @@ func TestParseEUI48(t *testing.T) {
 	}
 }
 
+func TestEUI48_LinkLocalAddr(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"00:11:22:33:44:55", "fe80::211:22ff:fe33:4455"},
+		{"02:11:22:33:44:55", "fe80::11:22ff:fe33:4455"},
+		{"aa-bb-cc-dd-ee-ff", "fe80::a8bb:ccff:fedd:eeff"},
+	}
+
+	for _, c := range cases {
+		eui, _ := ParseEUI48(c.given)
+		ip := eui.LinkLocalAddr()
+		if ip.String() != c.expect {
+			t.Errorf("%s.LinkLocalAddr() expected %s but was %s", c.given, c.expect, ip)
+		}
+	}
+}
+
 func TestEUI48_Strings(t *testing.T) {
 	cases := []struct {
 		given  string
@@ -46,6 +65,75 @@ func TestEUI48_Strings(t *testing.T) {
 	}
 }
 
+func TestEUI48_Classify(t *testing.T) {
+	cases := []struct {
+		given     string
+		oui       [3]byte
+		local     bool
+		multicast bool
+	}{
+		{"00-50-fe-00-00-01", [3]byte{0x00, 0x50, 0xfe}, false, false},
+		{"02-00-00-00-00-01", [3]byte{0x02, 0x00, 0x00}, true, false},
+		{"01-00-5e-00-00-fb", [3]byte{0x01, 0x00, 0x5e}, false, true},
+		{"ff-ff-ff-ff-ff-ff", [3]byte{0xff, 0xff, 0xff}, true, true},
+	}
+
+	for _, c := range cases {
+		eui, _ := ParseEUI48(c.given)
+		if oui := eui.OUI(); oui != c.oui {
+			t.Errorf("%s.OUI() expected %v but was %v", c.given, c.oui, oui)
+		}
+		if eui.IsLocallyAdministered() != c.local {
+			t.Errorf("%s.IsLocallyAdministered() expected %v", c.given, c.local)
+		}
+		if eui.IsMulticast() != c.multicast {
+			t.Errorf("%s.IsMulticast() expected %v", c.given, c.multicast)
+		}
+	}
+}
+
+func TestEUI48_StringWithSep(t *testing.T) {
+	cases := []struct {
+		given  string
+		sep    string
+		expect string
+		upper  string
+	}{
+		{"aa-bb-cc-dd-ee-ff", ":", "aa:bb:cc:dd:ee:ff", "AA:BB:CC:DD:EE:FF"},
+		{"AABB.CCDD.EEFF", "-", "aa-bb-cc-dd-ee-ff", "AA-BB-CC-DD-EE-FF"},
+		{"00:50:fe:00:00:01", "", "0050fe000001", "0050FE000001"},
+		{"00:00:00:00:00:00", ":", "00:00:00:00:00:00", "00:00:00:00:00:00"},
+	}
+
+	for _, c := range cases {
+		eui, _ := ParseEUI48(c.given)
+		if str := eui.StringWithSep(c.sep); str != c.expect {
+			t.Errorf("%s.StringWithSep(%q) expected %s but was %s", c.given, c.sep, c.expect, str)
+		}
+		if str := eui.StringWithSepUpper(c.sep); str != c.upper {
+			t.Errorf("%s.StringWithSepUpper(%q) expected %s but was %s", c.given, c.sep, c.upper, str)
+		}
+	}
+}
+
+func TestEUI48_CiscoString(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"aa-bb-cc-dd-ee-ff", "aabb.ccdd.eeff"},
+		{"00:50:fe:00:00:01", "0050.fe00.0001"},
+		{"0050.fe00.0001", "0050.fe00.0001"},
+	}
+
+	for _, c := range cases {
+		eui, _ := ParseEUI48(c.given)
+		if str := eui.CiscoString(); str != c.expect {
+			t.Errorf("%s.CiscoString() expected %s but was %s", c.given, c.expect, str)
+		}
+	}
+}
+
 func TestEUI48_ToEUI64(t *testing.T) {
 	cases := []struct {
 		given  string
@@ -56,13 +56,25 @@ func (eui EUI64) String() string {
 }
 
 // ToIPv6 generates an IPv6 address from this EUI64 address and the provided IPv6Net.
+// The modified EUI-64 (see ToModifiedEUI64) is used as the interface identifier.
 // Nil will be returned if IPv6Net is not a /64.
 func (eui EUI64) ToIPv6(net *IPv6Net) *IPv6 {
 	if net.m128.prefixLen != 64 {
 		return nil
 	}
+	return NewIPv6(net.base.netId, uint64(eui.ToModifiedEUI64()))
+}
+
+/*
+ToModifiedEUI64 returns the modified EUI-64 form of this EUI64, as used for
+IPv6 interface identifiers (rfc4291 appendix A).
 
-	// set u/l bit to 0
-	hostId := uint64(eui) ^ 0x0200000000000000
-	return NewIPv6(net.base.netId, hostId)
+The modification inverts the universal/local (U/L) bit, which is the
+second-least-significant bit of the first octet (0x02). A universally
+administered address (U/L bit 0) therefore has the bit set to 1 in its
+modified form, and vice versa. For example, 00-11-22-ff-fe-33-44-55
+becomes 02-11-22-ff-fe-33-44-55. Note that the bit is flipped, not simply set.
+*/
+func (eui EUI64) ToModifiedEUI64() EUI64 {
+	return eui ^ 0x0200000000000000
 }
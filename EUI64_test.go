@@ -77,3 +77,22 @@ func TestEUI64_ToIPv6(t *testing.T) {
 		}
 	}
 }
+
+func TestEUI64_ToModifiedEUI64(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"00-11-22-ff-fe-33-44-55", "02-11-22-ff-fe-33-44-55"}, // universal -> local bit set
+		{"02-11-22-ff-fe-33-44-55", "00-11-22-ff-fe-33-44-55"}, // bit is flipped, not set
+		{"aa-bb-cc-dd-ee-ff-00-11", "a8-bb-cc-dd-ee-ff-00-11"},
+	}
+
+	for _, c := range cases {
+		eui, _ := ParseEUI64(c.given)
+		mod := eui.ToModifiedEUI64()
+		if mod.String() != c.expect {
+			t.Errorf("%s.ToModifiedEUI64() expected %s but was %s", c.given, c.expect, mod)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package netaddr
+
+// HostView provides index-based access to the usable host addresses of an IPv4Net
+// without materializing them. See IPv4Net.Hosts().
+type HostView struct {
+	first uint32 // first usable address
+	len   uint32 // number of usable addresses
+}
+
+// At returns the usable host address at index i, or nil if i is out of range.
+func (view *HostView) At(i uint32) *IPv4 {
+	if i >= view.len {
+		return nil
+	}
+	return NewIPv4(view.first + i)
+}
+
+// Len returns the number of usable host addresses within the view.
+func (view *HostView) Len() uint32 {
+	return view.len
+}
@@ -1,7 +1,12 @@
 package netaddr
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"math/bits"
+	stdnet "net"
+	"net/netip"
+	"strconv"
 	"strings"
 )
 
@@ -25,16 +30,133 @@ func ParseIPv4(ip string) (*IPv4, error) {
 	return &IPv4{addr: addr}, nil
 }
 
+// MustParseIPv4 is like ParseIPv4 but panics if the string cannot be parsed.
+// It simplifies the safe initialization of global variables and test fixtures.
+func MustParseIPv4(ip string) *IPv4 {
+	parsed, err := ParseIPv4(ip)
+	if err != nil {
+		panic(fmt.Sprintf("MustParseIPv4(%q): %s", ip, err.Error()))
+	}
+	return parsed
+}
+
+// ParseIPv4OrDefault is like ParseIPv4 but returns def if the string cannot be parsed.
+// It simplifies the handling of optional configuration values.
+func ParseIPv4OrDefault(ip string, def *IPv4) *IPv4 {
+	parsed, err := ParseIPv4(ip)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+/*
+ParseIPv4Loose parses a string into an IPv4 type, additionally accepting the historical
+forms understood by inet_aton(3) which are frequently used to obfuscate addresses:
+	* each part may be hex with a 0x prefix (eg. 0xC0.0xA8.0x01.0x01) or octal with a leading 0 (eg. 0300.0250.01.01)
+	* fewer than 4 parts, where the last part fills the remaining bytes (eg. 192.168.257 or 192.11010305)
+	* a single integer (eg. 3232235777)
+Use ParseIPv4 to accept only the dotted-quad format.
+*/
+func ParseIPv4Loose(ip string) (*IPv4, error) {
+	ip = strings.TrimSpace(ip)
+	parts := strings.Split(ip, ".")
+	if len(parts) > 4 {
+		return nil, fmt.Errorf("Error parsing '%s'. IPv4 address must have at most 4 parts.", ip)
+	}
+	var addr uint32
+	for i, part := range parts {
+		base := 10
+		if strings.HasPrefix(part, "0x") || strings.HasPrefix(part, "0X") {
+			base, part = 16, part[2:]
+		} else if len(part) > 1 && part[0] == '0' {
+			base, part = 8, part[1:]
+		}
+		if i < len(parts)-1 {
+			u8, err := strconv.ParseUint(part, base, 8)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing '%s'. Part %d is invalid.", ip, i+1)
+			}
+			addr |= uint32(u8) << uint(24-8*i)
+		} else { // last part fills the remaining bytes
+			u32, err := strconv.ParseUint(part, base, 8*(5-len(parts)))
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing '%s'. Part %d is invalid.", ip, i+1)
+			}
+			addr |= uint32(u32)
+		}
+	}
+	return &IPv4{addr: addr}, nil
+}
+
 // NewIPv4 creates an IPv4 type from a uint32
 func NewIPv4(addr uint32) *IPv4 {
 	return &IPv4{addr: addr}
 }
 
+// IPv4FromBytes creates an IPv4 type from a slice of exactly 4 bytes in big-endian (network) order.
+func IPv4FromBytes(b []byte) (*IPv4, error) {
+	if len(b) != 4 {
+		return nil, fmt.Errorf("IPv4 address must be exactly 4 bytes. Received %d bytes.", len(b))
+	}
+	return &IPv4{uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])}, nil
+}
+
+// Add returns the IPv4 which is n addresses after this one,
+// or nil if the end of the address space would be exceeded.
+func (ip *IPv4) Add(n uint32) *IPv4 {
+	if n > F32-ip.addr {
+		return nil
+	}
+	return NewIPv4(ip.addr + n)
+}
+
 // Addr returns the internal uint32 address.
 func (ip *IPv4) Addr() uint32 {
 	return ip.addr
 }
 
+// AppendPTR appends the reverse DNS (PTR) name of this IPv4 to b and returns
+// the extended buffer. See PTR().
+func (ip *IPv4) AppendPTR(b []byte) []byte {
+	for shift := 0; shift < 32; shift += 8 {
+		b = strconv.AppendUint(b, uint64(ip.addr>>shift&0xff), 10)
+		b = append(b, '.')
+	}
+	return append(b, "in-addr.arpa."...)
+}
+
+// AppendTo appends the dotted-quad form of this IPv4 (see String) to b and returns
+// the extended buffer.
+func (ip *IPv4) AppendTo(b []byte) []byte {
+	for shift := 24; shift >= 0; shift -= 8 {
+		b = strconv.AppendUint(b, uint64(ip.addr>>uint(shift)&0xff), 10)
+		if shift != 0 {
+			b = append(b, '.')
+		}
+	}
+	return b
+}
+
+// Bit returns the value (0 or 1) of the bit at position pos, where position 0 is the
+// most significant bit. Positions beyond 31 return 0.
+func (ip *IPv4) Bit(pos uint) int {
+	if pos > 31 {
+		return 0
+	}
+	return int(ip.addr>>(31-pos)) & 1
+}
+
+// Bytes returns a slice containing each byte of the IPv4 in big-endian (network) order.
+func (ip *IPv4) Bytes() []byte {
+	return []byte{byte(ip.addr >> 24), byte(ip.addr >> 16), byte(ip.addr >> 8), byte(ip.addr)}
+}
+
+// Clone returns an independent copy of this IPv4.
+func (ip *IPv4) Clone() *IPv4 {
+	return &IPv4{addr: ip.addr}
+}
+
 /*
 Cmp compares equality with another IPv4. Return:
 	* 1 if this IPv4 is numerically greater
@@ -55,6 +177,162 @@ func (ip *IPv4) Cmp(other *IPv4) (int, error) {
 	return 0, nil
 }
 
+// CommonPrefixLen returns the number of leading bits which this address shares with other.
+// Returns 0 if other is nil.
+func (ip *IPv4) CommonPrefixLen(other *IPv4) uint {
+	if other == nil {
+		return 0
+	}
+	return uint(bits.LeadingZeros32(ip.addr ^ other.addr))
+}
+
+// Distance returns the number of addresses between this IPv4 and other.
+// The result is negative if other is numerically less than this IPv4.
+func (ip *IPv4) Distance(other *IPv4) (int64, error) {
+	if other == nil {
+		return 0, fmt.Errorf("Argument other must not be nil.")
+	}
+	return int64(other.addr) - int64(ip.addr), nil
+}
+
+// DottedHex returns the address with each octet in hex, eg. 0xc0.0xa8.0x01.0x01
+func (ip *IPv4) DottedHex() string {
+	b := ip.Bytes()
+	return fmt.Sprintf("0x%02x.0x%02x.0x%02x.0x%02x", b[0], b[1], b[2], b[3])
+}
+
+/*
+Format implements fmt.Formatter, supporting the following verbs:
+	* %s, %v - dotted-quad format (eg. 192.168.1.1)
+	* %x, %X - 32-bit hex (eg. c0a80101)
+	* %b - 32-bit binary (eg. 11000000101010000000000100000001)
+	* %#v - debug format (eg. IPv4{addr:0xc0a80101 (192.168.1.1)})
+Flags and width are honored for %s and %v.
+*/
+func (ip *IPv4) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'x':
+		fmt.Fprintf(f, "%08x", ip.addr)
+	case verb == 'X':
+		fmt.Fprintf(f, "%08X", ip.addr)
+	case verb == 'b':
+		fmt.Fprintf(f, "%032b", ip.addr)
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, "IPv4{addr:0x%08x (%s)}", ip.addr, ip)
+	default:
+		fmt.Fprintf(f, formatDirective(f, verb), ip.String())
+	}
+}
+
+// GobDecode implements gob.GobDecoder using the format of UnmarshalBinary.
+func (ip *IPv4) GobDecode(data []byte) error {
+	return ip.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder using the format of MarshalBinary.
+func (ip *IPv4) GobEncode() ([]byte, error) {
+	return ip.MarshalBinary()
+}
+
+// IsAdminScopedMulticast returns true if this is an administratively scoped
+// multicast address (rfc2365), ie. 239.0.0.0/8.
+func (ip *IPv4) IsAdminScopedMulticast() bool {
+	return ip.addr>>24 == 239
+}
+
+// IsDocumentation returns true if this address is reserved for use in documentation
+// (rfc5737), ie. 192.0.2.0/24, 198.51.100.0/24, or 203.0.113.0/24.
+func (ip *IPv4) IsDocumentation() bool {
+	net24 := ip.addr >> 8
+	return net24 == 0xc00002 || net24 == 0xc63364 || net24 == 0xcb0071
+}
+
+// IsGLOPMulticast returns true if this is a GLOP multicast address (rfc3180), ie. within
+// 233.0.0.0/8 but excluding 233.252.0.0/14, which rfc5771 reserves for other use.
+func (ip *IPv4) IsGLOPMulticast() bool {
+	return ip.addr>>24 == 233 && ip.addr>>18 != 0x3a7f
+}
+
+// IsGlobalUnicast returns true if this is a global unicast address. Following the
+// behavior of the standard library, this is any address other than the unspecified,
+// loopback, link-local, multicast, and limited broadcast (255.255.255.255) addresses.
+func (ip *IPv4) IsGlobalUnicast() bool {
+	return !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsLinkLocal() && !ip.IsMulticast() &&
+		ip.addr != F32
+}
+
+// IsInternetworkControlMulticast returns true if this address is within the
+// Internetwork Control Block (rfc5771), ie. 224.0.1.0/24.
+func (ip *IPv4) IsInternetworkControlMulticast() bool {
+	return ip.addr>>8 == 0xe00001
+}
+
+// IsLinkLocal returns true if this is a link-local unicast address (169.254.0.0/16).
+func (ip *IPv4) IsLinkLocal() bool {
+	return ip.addr>>16 == 0xa9fe
+}
+
+// IsLocalControlMulticast returns true if this address is within the Local Network
+// Control Block (rfc5771), ie. 224.0.0.0/24, which is never forwarded by routers.
+func (ip *IPv4) IsLocalControlMulticast() bool {
+	return ip.addr>>8 == 0xe00000
+}
+
+// IsLoopback returns true if this is a loopback address (127.0.0.0/8).
+func (ip *IPv4) IsLoopback() bool {
+	return ip.addr>>24 == 127
+}
+
+// IsMulticast returns true if this is a multicast address (224.0.0.0/4).
+func (ip *IPv4) IsMulticast() bool {
+	return ip.addr>>28 == 0xe
+}
+
+// IsPrivate returns true if this is a private address (rfc1918),
+// ie. 10.0.0.0/8, 172.16.0.0/12, or 192.168.0.0/16.
+func (ip *IPv4) IsPrivate() bool {
+	return ip.addr>>24 == 10 || ip.addr>>20 == 0xac1 || ip.addr>>16 == 0xc0a8
+}
+
+// IsSourceSpecificMulticast returns true if this is a Source-Specific Multicast
+// address (rfc4607), ie. 232.0.0.0/8.
+func (ip *IPv4) IsSourceSpecificMulticast() bool {
+	return ip.addr>>24 == 232
+}
+
+// IsUnspecified returns true if this is the unspecified address (0.0.0.0).
+func (ip *IPv4) IsUnspecified() bool {
+	return ip.addr == 0
+}
+
+// Less reports whether this IPv4 is numerically less than other. Unlike Cmp it never
+// fails; a nil IPv4 is treated as greater than any other, so that nil entries sort last
+// when Less is used with sort.Slice or container/heap.
+func (ip *IPv4) Less(other *IPv4) bool {
+	if ip == nil {
+		return false
+	}
+	if other == nil {
+		return true
+	}
+	return ip.addr < other.addr
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the 4 bytes of the address
+// in big-endian order (see Bytes).
+func (ip *IPv4) MarshalBinary() ([]byte, error) {
+	return ip.Bytes(), nil
+}
+
+// Mask returns the network address formed by applying a netmask of the given prefix length
+// to this IPv4, without the need to construct an IPv4Net.
+func (ip *IPv4) Mask(prefixLen uint) (*IPv4, error) {
+	if prefixLen > 32 {
+		return nil, fmt.Errorf("Netmask length %d is too long for IPv4.", prefixLen)
+	}
+	return &IPv4{ip.addr & (F32 ^ F32>>prefixLen)}, nil
+}
+
 // MulticastMac returns the multicast mac-address for this IP.
 // It will return a value of 0 for addresses outside of the
 // multicast range 224.0.0.0/4.
@@ -67,6 +345,35 @@ func (ip *IPv4) MulticastMac() EUI48 {
 	return mac
 }
 
+/*
+MulticastScope classifies a multicast address by the sub-range of 224.0.0.0/4 it
+belongs to. Returns one of:
+	* "local-control" for 224.0.0.0/24 (see IsLocalControlMulticast)
+	* "internetwork-control" for 224.0.1.0/24 (see IsInternetworkControlMulticast)
+	* "source-specific" for 232.0.0.0/8 (see IsSourceSpecificMulticast)
+	* "glop" for 233.0.0.0/8, less 233.252.0.0/14 (see IsGLOPMulticast)
+	* "admin-scoped" for 239.0.0.0/8 (see IsAdminScopedMulticast)
+	* "other" for any other multicast address
+	* "" if this is not a multicast address
+*/
+func (ip *IPv4) MulticastScope() string {
+	switch {
+	case !ip.IsMulticast():
+		return ""
+	case ip.IsLocalControlMulticast():
+		return "local-control"
+	case ip.IsInternetworkControlMulticast():
+		return "internetwork-control"
+	case ip.IsSourceSpecificMulticast():
+		return "source-specific"
+	case ip.IsGLOPMulticast():
+		return "glop"
+	case ip.IsAdminScopedMulticast():
+		return "admin-scoped"
+	}
+	return "other"
+}
+
 // Next returns the next consecutive IPv4 or nil if the end of the address space is reached.
 func (ip *IPv4) Next() *IPv4 {
 	if ip.addr == F32{
@@ -75,6 +382,13 @@ func (ip *IPv4) Next() *IPv4 {
 	return NewIPv4(ip.addr + 1)
 }
 
+// OctalEscaped returns the address with each octet in octal, each with a leading 0,
+// eg. 0300.0250.01.01
+func (ip *IPv4) OctalEscaped() string {
+	b := ip.Bytes()
+	return fmt.Sprintf("0%o.0%o.0%o.0%o", b[0], b[1], b[2], b[3])
+}
+
 // Prev returns the preceding IPv4 or nil if this is 0.0.0.0.
 func (ip *IPv4) Prev() *IPv4 {
 	if ip.addr == 0{
@@ -83,13 +397,52 @@ func (ip *IPv4) Prev() *IPv4 {
 	return NewIPv4(ip.addr - 1)
 }
 
+// PTR returns the reverse DNS name of this IPv4 within the in-addr.arpa. zone,
+// eg. 1.1.168.192.in-addr.arpa.
+// Use AppendPTR() to avoid allocation when generating many names.
+func (ip *IPv4) PTR() string {
+	return string(ip.AppendPTR(make([]byte, 0, 29)))
+}
+
+// Scan implements sql.Scanner. It accepts an address in string or []byte form.
+func (ip *IPv4) Scan(src interface{}) error {
+	str, err := scanString(src, "IPv4")
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseIPv4(str)
+	if err != nil {
+		return err
+	}
+	*ip = *parsed
+	return nil
+}
+
 // String return IPv4 address as a string.
+// Use AppendTo() to avoid allocation when formatting many addresses.
 func (ip *IPv4) String() string {
-	return fmt.Sprintf("%d.%d.%d.%d",
-		ip.addr>>24&0xff,
-		ip.addr>>16&0xff,
-		ip.addr>>8&0xff,
-		ip.addr&0xff)
+	return string(ip.AppendTo(make([]byte, 0, 15)))
+}
+
+// Sub returns the IPv4 which is n addresses before this one,
+// or nil if the start of the address space would be exceeded.
+func (ip *IPv4) Sub(n uint32) *IPv4 {
+	if n > ip.addr {
+		return nil
+	}
+	return NewIPv4(ip.addr - n)
+}
+
+// To6to4 returns the 6to4 (rfc3056) IPv6 address for this IPv4.
+// The IPv4 is embedded following the 2002::/16 prefix, eg. 2002:c0a8:101::
+func (ip *IPv4) To6to4() *IPv6 {
+	return NewIPv6(0x2002000000000000|uint64(ip.addr)<<16, 0)
+}
+
+// ToMapped returns the IPv4-mapped (rfc4291) IPv6 address for this IPv4,
+// eg. ::ffff:192.168.1.1
+func (ip *IPv4) ToMapped() *IPv6 {
+	return NewIPv6(0, 0xffff00000000|uint64(ip.addr))
 }
 
 // ToNet returns the IPv4 as a IPv4Net
@@ -97,4 +450,34 @@ func (ip *IPv4) ToNet() *IPv4Net{
 	return initIPv4Net(ip,nil)
 }
 
+// ToNetipAddr returns the IPv4 as a netip.Addr from the standard library.
+func (ip *IPv4) ToNetipAddr() netip.Addr {
+	return netip.AddrFrom4([4]byte{byte(ip.addr >> 24), byte(ip.addr >> 16), byte(ip.addr >> 8), byte(ip.addr)})
+}
+
+// ToStdIP returns the IPv4 as a 4-byte net.IP from the standard library.
+func (ip *IPv4) ToStdIP() stdnet.IP {
+	return stdnet.IP(ip.Bytes())
+}
+
+// Uint32String returns the address as a single decimal integer, eg. 3232235777
+func (ip *IPv4) Uint32String() string {
+	return strconv.FormatUint(uint64(ip.addr), 10)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. See MarshalBinary.
+func (ip *IPv4) UnmarshalBinary(data []byte) error {
+	parsed, err := IPv4FromBytes(data)
+	if err != nil {
+		return err
+	}
+	*ip = *parsed
+	return nil
+}
+
+// Value implements driver.Valuer. The address is stored in string form.
+func (ip *IPv4) Value() (driver.Value, error) {
+	return ip.String(), nil
+}
+
 func (ip *IPv4) Version() uint{return 4}
@@ -0,0 +1,66 @@
+package netaddr
+
+import (
+	"fmt"
+)
+
+// IPv4Allocator is a simple in-memory IPAM allocator which carves subnets out of a
+// parent IPv4Net, reusing released space. It is not safe for concurrent use.
+type IPv4Allocator struct {
+	parent    *IPv4Net
+	allocated IPv4NetList // sorted
+}
+
+// NewIPv4Allocator creates an IPv4Allocator with no allocations from the given parent network.
+func NewIPv4Allocator(parent *IPv4Net) (*IPv4Allocator, error) {
+	if parent == nil {
+		return nil, fmt.Errorf("Argument parent must not be nil.")
+	}
+	return &IPv4Allocator{parent: parent}, nil
+}
+
+// Allocate records and returns the first free subnet of the given prefix length (see
+// IPv4Net.NextFree). An error is returned if prefixLen is invalid for the parent
+// network, or if no free subnet of that size remains.
+func (alloc *IPv4Allocator) Allocate(prefixLen uint) (*IPv4Net, error) {
+	if prefixLen < alloc.parent.m32.prefixLen || prefixLen > 32 {
+		return nil, fmt.Errorf("Prefix length %d is not valid within %s.", prefixLen, alloc.parent)
+	}
+	net := alloc.parent.NextFree(prefixLen, alloc.allocated)
+	if net == nil {
+		return nil, fmt.Errorf("No free /%d networks remain within %s.", prefixLen, alloc.parent)
+	}
+	alloc.allocated = append(alloc.allocated, net).Sort()
+	return net, nil
+}
+
+// Allocated returns a sorted copy of the currently allocated networks.
+func (alloc *IPv4Allocator) Allocated() IPv4NetList {
+	return append(IPv4NetList{}, alloc.allocated...)
+}
+
+// Free returns the minimal list of networks covering the unallocated space of the
+// parent network (see IPv4Net.FreeSpace).
+func (alloc *IPv4Allocator) Free() IPv4NetList {
+	return alloc.parent.FreeSpace(alloc.allocated)
+}
+
+// Parent returns the network from which subnets are allocated.
+func (alloc *IPv4Allocator) Parent() *IPv4Net {
+	return alloc.parent
+}
+
+// Release returns a previously allocated network to the free space. An error is
+// returned if net does not exactly match an allocated network.
+func (alloc *IPv4Allocator) Release(net *IPv4Net) error {
+	if net == nil {
+		return fmt.Errorf("Argument net must not be nil.")
+	}
+	for i, e := range alloc.allocated {
+		if cmp, _ := e.Cmp(net); cmp == 0 {
+			alloc.allocated = append(alloc.allocated[:i], alloc.allocated[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("Network %s is not allocated.", net)
+}
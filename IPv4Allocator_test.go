@@ -0,0 +1,89 @@
+package netaddr
+
+import "testing"
+import "fmt"
+
+func Test_NewIPv4Allocator(t *testing.T) {
+	if alloc, err := NewIPv4Allocator(nil); err == nil || alloc != nil {
+		t.Errorf("NewIPv4Allocator(nil) Expect: nil and an error  Result: %v, %v", alloc, err)
+	}
+
+	parent, _ := ParseIPv4Net("10.0.0.0/24")
+	alloc, err := NewIPv4Allocator(parent)
+	if err != nil {
+		t.Fatalf("NewIPv4Allocator(%s) unexpected error: %s", parent, err)
+	}
+	if alloc.Parent() != parent || len(alloc.Allocated()) != 0 || fmt.Sprint(alloc.Free()) != "[10.0.0.0/24]" {
+		t.Errorf("NewIPv4Allocator(%s) Expect: an empty allocator  Result: %v %v", parent, alloc.Allocated(), alloc.Free())
+	}
+}
+
+func Test_IPv4Allocator_Allocate(t *testing.T) {
+	alloc, _ := NewIPv4Allocator(MustParseIPv4Net("10.0.0.0/24"))
+	cases := []struct {
+		prefixLen uint
+		expect    string
+	}{
+		{26, "10.0.0.0/26"},
+		{28, "10.0.0.64/28"},
+		{25, "10.0.0.128/25"},
+		{28, "10.0.0.80/28"},
+		{25, ""}, // no space left
+		{23, ""}, // larger than parent
+		{33, ""},
+	}
+
+	for _, c := range cases {
+		net, err := alloc.Allocate(c.prefixLen)
+		if c.expect == "" {
+			if err == nil {
+				t.Errorf("Allocate(%d) Expect: error  Result: %s", c.prefixLen, net)
+			}
+		} else if err != nil || net.String() != c.expect {
+			t.Errorf("Allocate(%d) Expect: %s  Result: %v, %v", c.prefixLen, c.expect, net, err)
+		}
+	}
+
+	expect := "[10.0.0.0/26 10.0.0.64/28 10.0.0.80/28 10.0.0.128/25]"
+	if allocated := alloc.Allocated(); fmt.Sprint(allocated) != expect {
+		t.Errorf("Allocated() Expect: %s  Result: %v", expect, allocated)
+	}
+	if free := alloc.Free(); fmt.Sprint(free) != "[10.0.0.96/27]" {
+		t.Errorf("Free() Expect: [10.0.0.96/27]  Result: %v", free)
+	}
+}
+
+func Test_IPv4Allocator_Release(t *testing.T) {
+	alloc, _ := NewIPv4Allocator(MustParseIPv4Net("10.0.0.0/24"))
+	first, _ := alloc.Allocate(25)
+	alloc.Allocate(25)
+
+	if err := alloc.Release(MustParseIPv4Net("10.0.0.0/26")); err == nil {
+		t.Errorf("Release(10.0.0.0/26) Expect: error for a network which is not allocated")
+	}
+	if err := alloc.Release(nil); err == nil {
+		t.Errorf("Release(nil) Expect: error")
+	}
+	if err := alloc.Release(first); err != nil {
+		t.Errorf("Release(%s) unexpected error: %s", first, err)
+	}
+	if err := alloc.Release(first); err == nil {
+		t.Errorf("Release(%s) Expect: error when released twice", first)
+	}
+	if free := alloc.Free(); fmt.Sprint(free) != "[10.0.0.0/25]" {
+		t.Errorf("Free() Expect: [10.0.0.0/25]  Result: %v", free)
+	}
+
+	// released space is reused
+	for _, expect := range []string{"10.0.0.0/26", "10.0.0.64/26"} {
+		if net, err := alloc.Allocate(26); err != nil || net.String() != expect {
+			t.Errorf("Allocate(26) Expect: %s  Result: %v, %v", expect, net, err)
+		}
+	}
+	if _, err := alloc.Allocate(32); err == nil {
+		t.Errorf("Allocate(32) Expect: error once the parent is full")
+	}
+	if free := alloc.Free(); len(free) != 0 {
+		t.Errorf("Free() Expect: []  Result: %v", free)
+	}
+}
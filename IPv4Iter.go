@@ -0,0 +1,18 @@
+package netaddr
+
+// IPv4Iter iterates over a sequence of IPv4 addresses. See IPv4Net.StepHosts().
+type IPv4Iter struct {
+	next uint64
+	end  uint64 // one past the final address
+	step uint64
+}
+
+// Next returns the next IPv4, or nil once the iterator is exhausted.
+func (iter *IPv4Iter) Next() *IPv4 {
+	if iter.next >= iter.end {
+		return nil
+	}
+	ip := NewIPv4(uint32(iter.next))
+	iter.next += iter.step
+	return ip
+}
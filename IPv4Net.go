@@ -1,7 +1,14 @@
 package netaddr
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	stdnet "net"
+	"net/netip"
 	"strings"
 )
 
@@ -11,6 +18,16 @@ type IPv4Net struct {
 	m32  *Mask32
 }
 
+// IPv4NetDetail is a detailed description of an IPv4Net, suitable for encoding to JSON.
+// See IPv4Net.AsDetailJSON().
+type IPv4NetDetail struct {
+	CIDR      string `json:"cidr"`
+	Network   string `json:"network"`
+	Broadcast string `json:"broadcast"`
+	Netmask   string `json:"netmask"`
+	HostCount uint32 `json:"hostCount"` // number of usable host addresses
+}
+
 /*
 ParseIPv4Net parses a string into an IPv4Net type. Accepts addresses in the form of:
 	* single IP (eg. 192.168.1.1 -- defaults to /32)
@@ -18,40 +35,58 @@ ParseIPv4Net parses a string into an IPv4Net type. Accepts addresses in the form
 	* extended format (eg. 192.168.1.1 255.255.255.0)
 */
 func ParseIPv4Net(addr string) (*IPv4Net, error) {
-	addr = strings.TrimSpace(addr)
-	var m32 *Mask32
-
-	// parse out netmask
-	if strings.Contains(addr, "/") { // cidr format
-		addrSplit := strings.Split(addr, "/")
-		if len(addrSplit) > 2 {
-			return nil, fmt.Errorf("IP address contains multiple '/' characters.")
-		}
-		addr = addrSplit[0]
-		prefixLen := addrSplit[1]
-		var err error
-		m32, err = ParseMask32(prefixLen)
-		if err != nil {
-			return nil, err
-		}
-	} else if strings.Contains(addr, " ") { // extended format
-		addrSplit := strings.SplitN(addr, " ", 2)
-		addr = addrSplit[0]
-		mask := addrSplit[1]
-		var err error
-		m32, err = ParseMask32(mask)
-		if err != nil {
-			return nil, err
-		}
+	ip, m32, err := parseIPv4Net(addr)
+	if err != nil {
+		return nil, err
 	}
+	return initIPv4Net(ip, m32), nil
+}
 
-	// parse ip
-	ip, err := ParseIPv4(addr)
+// ParseIPv4NetStrict is like ParseIPv4Net, but returns an error if the address
+// contains host bits (ie. it is not the network address for the given netmask).
+func ParseIPv4NetStrict(addr string) (*IPv4Net, error) {
+	ip, m32, err := parseIPv4Net(addr)
 	if err != nil {
 		return nil, err
 	}
+	net := initIPv4Net(ip, m32)
+	if net.base.addr != ip.addr {
+		return nil, fmt.Errorf("Error parsing '%s'. Address %s contains host bits. The network address is %s.",
+			strings.TrimSpace(addr), ip, net.base)
+	}
+	return net, nil
+}
 
-	return initIPv4Net(ip, m32), nil
+// ParseIPv4NetWithHostBits is like ParseIPv4Net, but additionally reports whether the
+// address contained host bits, which are masked off in the result as usual. This allows
+// linters to warn about input such as 10.0.0.1/8 without rejecting it (see ParseIPv4NetStrict).
+func ParseIPv4NetWithHostBits(addr string) (net *IPv4Net, hadHostBits bool, err error) {
+	ip, m32, err := parseIPv4Net(addr)
+	if err != nil {
+		return nil, false, err
+	}
+	net = initIPv4Net(ip, m32)
+	return net, net.base.addr != ip.addr, nil
+}
+
+// MustParseIPv4Net is like ParseIPv4Net but panics if the string cannot be parsed.
+// It simplifies the safe initialization of global variables and test fixtures.
+func MustParseIPv4Net(addr string) *IPv4Net {
+	parsed, err := ParseIPv4Net(addr)
+	if err != nil {
+		panic(fmt.Sprintf("MustParseIPv4Net(%q): %s", addr, err.Error()))
+	}
+	return parsed
+}
+
+// ParseIPv4NetOrDefault is like ParseIPv4Net but returns def if the string cannot be parsed.
+// It simplifies the handling of optional configuration values.
+func ParseIPv4NetOrDefault(addr string, def *IPv4Net) *IPv4Net {
+	parsed, err := ParseIPv4Net(addr)
+	if err != nil {
+		return def
+	}
+	return parsed
 }
 
 // NewIPv4Net creates a IPv4Net type from a IPv4 and Mask32.
@@ -63,6 +98,90 @@ func NewIPv4Net(ip *IPv4, m32 *Mask32) (*IPv4Net, error) {
 	return initIPv4Net(ip, m32), nil
 }
 
+// IPv4NetFromStdIPNet creates an IPv4Net type from a net.IPNet of the standard library.
+// The mask must be in canonical form.
+func IPv4NetFromStdIPNet(n *stdnet.IPNet) (*IPv4Net, error) {
+	if n == nil {
+		return nil, fmt.Errorf("Argument n must not be nil.")
+	}
+	ip := n.IP.To4()
+	ones, bits := n.Mask.Size()
+	if ip == nil || bits != 32 {
+		return nil, fmt.Errorf("Network %s is not a valid IPv4 network.", n)
+	}
+	addr, _ := IPv4FromBytes(ip)
+	return initIPv4Net(addr, initMask32(uint(ones))), nil
+}
+
+// AdjacentTo returns true if this IPv4Net and other are equally sized siblings which form
+// the two halves of a common supernet, ie. they may be summarized with Summ. Unlike Summ
+// it does not allocate, and it returns false if other is nil, for two identical networks,
+// and for /0 networks (which have no supernet).
+func (net *IPv4Net) AdjacentTo(other *IPv4Net) bool {
+	if other == nil || net.m32.prefixLen != other.m32.prefixLen || net.m32.prefixLen == 0 {
+		return false
+	}
+	return net.base.addr^other.base.addr == uint32(1)<<(32-net.m32.prefixLen)
+}
+
+// Align returns a copy of the network with the netmask re-applied to the network address.
+// Since an IPv4Net is always masked when it is constructed (see NewIPv4Net), the result is
+// equal to this IPv4Net; this method exists to make that guarantee explicit at call sites.
+func (net *IPv4Net) Align() *IPv4Net {
+	return initIPv4Net(net.base, net.m32)
+}
+
+// AllHosts returns the usable host addresses of this network (see Hosts) as an IPv4List.
+// To guard against accidentally allocating very large lists, an error is returned if
+// the number of usable addresses exceeds limit. Use Hosts() or StepHosts() for large networks.
+func (net *IPv4Net) AllHosts(limit uint32) (IPv4List, error) {
+	hosts := net.Hosts()
+	if hosts.Len() > limit {
+		return nil, fmt.Errorf("Network %s contains %d usable hosts, which exceeds the limit of %d.", net, hosts.Len(), limit)
+	}
+	list := make(IPv4List, hosts.Len())
+	for i := range list {
+		list[i] = hosts.At(uint32(i))
+	}
+	return list, nil
+}
+
+// AsDetailJSON returns an IPv4NetDetail describing this IPv4Net, which encodes to JSON as:
+//	{"cidr":"10.0.0.0/24","network":"10.0.0.0","broadcast":"10.0.0.255","netmask":"255.255.255.0","hostCount":254}
+// The host count excludes the network and broadcast addresses for /30 and shorter networks.
+func (net *IPv4Net) AsDetailJSON() IPv4NetDetail {
+	return IPv4NetDetail{
+		CIDR:      net.String(),
+		Network:   net.base.String(),
+		Broadcast: NewIPv4(net.base.addr | (net.m32.mask ^ F32)).String(),
+		Netmask:   net.m32.Extended(),
+		HostCount: net.m32.usable(),
+	}
+}
+
+/*
+BitBreakdown returns the given address as a pair of binary strings, split at the prefix
+length boundary of the IPv4Net. If ip is nil then the network address is used.
+For example, 192.168.1.130 within 192.168.1.128/25 returns:
+	* networkBits: 1100000010101000000000011
+	* hostBits: 0000010
+*/
+func (net *IPv4Net) BitBreakdown(ip *IPv4) (networkBits, hostBits string) {
+	if ip == nil {
+		ip = net.base
+	}
+	bits := fmt.Sprintf("%032b", ip.addr)
+	return bits[:net.m32.prefixLen], bits[net.m32.prefixLen:]
+}
+
+// Clone returns an independent copy of this IPv4Net. Unlike the networks returned by
+// methods such as NextSib, the copy shares neither its network address nor its netmask
+// with the original.
+func (net *IPv4Net) Clone() *IPv4Net {
+	m32 := *net.m32
+	return &IPv4Net{net.base.Clone(), &m32}
+}
+
 /*
 Cmp compares equality with another IPv4Net. Return:
 	* 1 if this IPv4Net is numerically greater
@@ -88,6 +207,42 @@ func (net *IPv4Net) Cmp(other *IPv4Net) (int, error) {
 	return net.m32.Cmp(other.m32), nil
 }
 
+/*
+CmpNet behaves like Cmp, but never fails, making it suitable for use as a three-way
+comparator (eg. with slices.SortFunc). A nil IPv4Net is ordered before every other
+IPv4Net, and two nil IPv4Nets are equal. Note that this differs from IPv4.Less, which
+orders nil last.
+*/
+func (net *IPv4Net) CmpNet(other *IPv4Net) int {
+	if net == nil || other == nil {
+		if net == other {
+			return 0
+		} else if net == nil {
+			return -1
+		}
+		return 1
+	}
+	res, _ := net.Cmp(other)
+	return res
+}
+
+// CommonSupernet returns the smallest network which contains both this IPv4Net and other,
+// or nil if other is nil. Unlike Summ(), the two networks need not be adjacent or of
+// equal size. The result may be as large as 0.0.0.0/0.
+func (net *IPv4Net) CommonSupernet(other *IPv4Net) *IPv4Net {
+	if other == nil {
+		return nil
+	}
+	prefixLen := uint(bits.LeadingZeros32(net.base.addr ^ other.base.addr))
+	if net.m32.prefixLen < prefixLen {
+		prefixLen = net.m32.prefixLen
+	}
+	if other.m32.prefixLen < prefixLen {
+		prefixLen = other.m32.prefixLen
+	}
+	return initIPv4Net(net.base, initMask32(prefixLen))
+}
+
 // Contains returns true if the IPv4Net contains the IPv4
 func (net *IPv4Net) Contains(ip *IPv4) bool {
 	if ip != nil {
@@ -98,6 +253,93 @@ func (net *IPv4Net) Contains(ip *IPv4) bool {
 	return false
 }
 
+// ContainsAll returns true if the IPv4Net contains every IPv4 in ips.
+// It returns true for an empty list.
+func (net *IPv4Net) ContainsAll(ips IPv4List) bool {
+	for _, ip := range ips {
+		if !net.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAny returns true if the IPv4Net contains at least one IPv4 in ips.
+// It returns false for an empty list.
+func (net *IPv4Net) ContainsAny(ips IPv4List) bool {
+	for _, ip := range ips {
+		if net.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsIP implements IPNet. It returns true if ip is an *IPv4 contained by this IPv4Net.
+func (net *IPv4Net) ContainsIP(ip IP) bool {
+	ip4, ok := ip.(*IPv4)
+	return ok && net.Contains(ip4)
+}
+
+/*
+Decompose splits the position of ip within this network into components
+according to the prefix boundaries given by levels. For each level, the index
+of the subnet of that prefix length containing ip (relative to the previous level)
+is returned, followed by the index of ip within the subnet of the final level.
+Levels must be in ascending order, longer than the prefix of this network, and no
+greater than 32. For example:
+
+	net, _ := ParseIPv4Net("10.0.0.0/24")
+	ip, _ := ParseIPv4("10.0.0.130")
+	net.Decompose(ip, []uint{26}) // [2 2], the 3rd /26 and the 3rd address within it
+*/
+func (net *IPv4Net) Decompose(ip *IPv4, levels []uint) ([]uint32, error) {
+	if ip == nil {
+		return nil, fmt.Errorf("Argument ip must not be nil.")
+	}
+	if !net.Contains(ip) {
+		return nil, fmt.Errorf("IP %s is not contained within network %s.", ip, net)
+	}
+
+	offset := uint64(ip.addr - net.base.addr)
+	prev := net.m32.prefixLen
+	components := make([]uint32, 0, len(levels)+1)
+	for _, level := range levels {
+		if level <= prev || level > 32 {
+			return nil, fmt.Errorf("Level /%d is invalid. Levels must be ascending and between /%d and /32.",
+				level, net.m32.prefixLen+1)
+		}
+		bits := uint64(1)<<(level-prev) - 1
+		components = append(components, uint32(offset>>(32-level)&bits))
+		prev = level
+	}
+	components = append(components, uint32(offset&(uint64(1)<<(32-prev)-1)))
+	return components, nil
+}
+
+// EqualCIDRString returns true if this IPv4Net is equal to the network described by the
+// given string, which must be in CIDR format (eg. 192.168.1.0/24) or a single IP
+// (defaults to /32). As with ParseIPv4Net any host bits are masked off before comparison.
+// Unlike ParseIPv4Net followed by Cmp, this method does not allocate. It returns false
+// if the string cannot be parsed.
+func (net *IPv4Net) EqualCIDRString(s string) bool {
+	s = strings.TrimSpace(s)
+	var prefixLen uint32 = 32
+	if i := strings.IndexByte(s, '/'); i != -1 {
+		var ok bool
+		if prefixLen, ok = parseDecimal(s[i+1:], 32); !ok {
+			return false
+		}
+		s = s[:i]
+	}
+	addr, ok := parseDottedQuad(s)
+	if !ok {
+		return false
+	}
+	mask := F32 ^ (F32 >> prefixLen)
+	return net.m32.prefixLen == uint(prefixLen) && net.base.addr == addr&mask
+}
+
 // Extended returns the network address as a string in extended format.
 func (net *IPv4Net) Extended() string {
 	return net.base.String() + " " + net.m32.Extended()
@@ -105,7 +347,7 @@ func (net *IPv4Net) Extended() string {
 
 // Fill returns a copy of the given IPv4NetList, stripped of
 // any networks which are not subnets of this IPv4Net, and
-// with any missing gaps filled in.
+// with any missing gaps filled in with the fewest possible networks.
 func (net *IPv4Net) Fill(list IPv4NetList) IPv4NetList {
 	var subs IPv4NetList
 	// get rid of non subnets
@@ -117,42 +359,222 @@ func (net *IPv4Net) Fill(list IPv4NetList) IPv4NetList {
 			}
 		}
 		// discard subnets of subnets & sort
-		subs = subs.discardSubnets().Sort()
+		if len(subs) > 0 {
+			subs = subs.discardSubnets().Sort()
+		}
 	} else {
 		return subs
 	}
 
-	// fill
+	// fill the gaps before, between and after the subnets
 	var filled IPv4NetList
 	if len(subs) > 0 {
-		// bottom fill if base address is missing
-		base := net.base.addr
-		if subs[0].base.addr != base {
-			filled = subs[0].backfill(base)
+		cur := uint64(net.base.addr)
+		for _, sub := range subs {
+			filled = append(filled, ipv4RangeNets(cur, uint64(sub.base.addr))...)
+			filled = append(filled, sub)
+			cur = uint64(sub.base.addr) + uint64(1)<<(32-sub.m32.prefixLen)
 		}
+		end := uint64(net.base.addr) + uint64(1)<<(32-net.m32.prefixLen)
+		filled = append(filled, ipv4RangeNets(cur, end)...)
+	}
+	return filled
+}
 
-		// fill gaps between subnets
-		sib := net.nthNextSib(1)
-		var ceil uint32
-		if sib != nil {
-			ceil = sib.base.addr
-		} else {
-			ceil = F32
+// FillExact behaves like Fill, but returns an error identifying the first
+// entry of the given IPv4NetList which is not a subnet of this IPv4Net
+// instead of silently discarding it.
+func (net *IPv4Net) FillExact(list IPv4NetList) (IPv4NetList, error) {
+	for i, e := range list {
+		if isRel, rel := net.Rel(e); !isRel || rel != 1 {
+			return nil, fmt.Errorf("Item index %d (%s) is not a subnet of %s.", i, e, net)
 		}
-		for i := 0; i < len(subs); i += 1 {
-			sub := subs[i]
-			filled = append(filled, sub)
-			// we need to define a limit for this round
-			var limit uint32
-			if i+1 < len(subs) {
-				limit = subs[i+1].base.addr
-			} else {
-				limit = ceil
-			}
-			filled = append(filled, sub.fwdFill(limit)...)
+	}
+	return net.Fill(list), nil
+}
+
+// FirstUsable returns the first usable host address of this network (see Hosts).
+// For /31 networks (rfc3021) and /32 networks this is the network address itself.
+func (net *IPv4Net) FirstUsable() *IPv4 {
+	return net.Hosts().At(0)
+}
+
+/*
+Format implements fmt.Formatter, supporting the following verbs:
+	* %s, %v - CIDR format (eg. 192.168.1.0/24)
+	* %#v - debug format (eg. IPv4Net{base:192.168.1.0 netmask:255.255.255.0 prefixLen:24})
+Flags and width are honored for %s and %v.
+*/
+func (net *IPv4Net) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprintf(f, "IPv4Net{base:%s netmask:%s prefixLen:%d}", net.base, net.m32.Extended(), net.m32.prefixLen)
+		return
+	}
+	fmt.Fprintf(f, formatDirective(f, verb), net.String())
+}
+
+// FreeSpace returns the minimal list of networks covering the portions of this IPv4Net
+// which are not covered by any of the allocated networks, in ascending order. As with
+// Fill, any entries of allocated which are not subnets of this IPv4Net are ignored. If
+// allocated contains this IPv4Net (or a supernet of it) then there is no free space.
+func (net *IPv4Net) FreeSpace(allocated IPv4NetList) IPv4NetList {
+	var subs IPv4NetList
+	for _, e := range allocated {
+		if isRel, rel := net.Rel(e); isRel && rel == 1 {
+			subs = append(subs, e)
+		} else if isRel { // e covers the whole of this network
+			return IPv4NetList{}
 		}
 	}
-	return filled
+	if len(subs) > 0 {
+		subs = subs.discardSubnets().Sort()
+	}
+
+	var free IPv4NetList
+	cur := uint64(net.base.addr)
+	for _, sub := range subs {
+		free = append(free, ipv4RangeNets(cur, uint64(sub.base.addr))...)
+		cur = uint64(sub.base.addr) + uint64(1)<<(32-sub.m32.prefixLen)
+	}
+	end := uint64(net.base.addr) + uint64(1)<<(32-net.m32.prefixLen)
+	return append(free, ipv4RangeNets(cur, end)...)
+}
+
+// GobDecode implements gob.GobDecoder using the format of UnmarshalBinary.
+func (net *IPv4Net) GobDecode(data []byte) error {
+	return net.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder using the format of MarshalBinary.
+func (net *IPv4Net) GobEncode() ([]byte, error) {
+	return net.MarshalBinary()
+}
+
+// Grow returns the largest network which starts at the network address of this IPv4Net,
+// ie. the prefix length is decreased as far as possible without crossing a bit boundary.
+// If no larger network exists (eg. for 0.0.0.0/0 or 10.0.0.1/32) then an equal IPv4Net is returned.
+func (net *IPv4Net) Grow() *IPv4Net {
+	return net.grow()
+}
+
+// HeadSubnet returns the first subnet of the given prefix length within this IPv4Net.
+// It is equivalent to NthSubnet(prefixLen, 0) and returns nil under the same conditions.
+func (net *IPv4Net) HeadSubnet(prefixLen uint) *IPv4Net {
+	return net.NthSubnet(prefixLen, 0)
+}
+
+// HostAtPercent returns the address located at fraction p (0.0 - 1.0) of the way through
+// this network, rounded to the nearest address. 0.0 returns the network address and 1.0
+// the broadcast address. Values of p outside of this range are clamped to it.
+func (net *IPv4Net) HostAtPercent(p float64) *IPv4 {
+	if !(p > 0) { // includes NaN
+		p = 0
+	} else if p > 1 {
+		p = 1
+	}
+	last := uint64(net.m32.mask^F32)
+	return NewIPv4(net.base.addr + uint32(math.Round(p*float64(last))))
+}
+
+// Hosts returns a HostView providing index-based access to the usable host addresses of
+// this network. For /30 and shorter networks the network and broadcast addresses are
+// excluded, so index 0 is the first address following the network address.
+func (net *IPv4Net) Hosts() *HostView {
+	view := &HostView{first: net.base.addr, len: net.m32.usable()}
+	if net.m32.prefixLen < 31 {
+		view.first += 1
+	}
+	return view
+}
+
+/*
+HostsContext streams the usable host addresses of this network (see Hosts) in ascending
+order over the returned channel, which is closed once every address has been sent or ctx
+is done, whichever comes first.
+
+The addresses are produced by a goroutine which blocks until each one is received. A
+consumer which stops receiving before the channel is closed must cancel ctx, after which
+the goroutine exits promptly and closes the channel; otherwise the goroutine is leaked.
+*/
+func (net *IPv4Net) HostsContext(ctx context.Context) <-chan *IPv4 {
+	hosts := net.Hosts()
+	ch := make(chan *IPv4)
+	go func() {
+		defer close(ch)
+		for i := uint64(0); i < uint64(hosts.Len()); i += 1 {
+			if ctx.Err() != nil { // select picks randomly when both cases are ready
+				return
+			}
+			select {
+			case ch <- hosts.At(uint32(i)):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// IsAligned returns true if the network address contains no host bits. See Align().
+func (net *IPv4Net) IsAligned() bool {
+	return net.base.addr&net.m32.mask == net.base.addr
+}
+
+// IsDefaultRoute returns true if this is the default route (0.0.0.0/0).
+func (net *IPv4Net) IsDefaultRoute() bool {
+	return net.m32.prefixLen == 0
+}
+
+// IsHostRoute returns true if this is a host route (/32).
+func (net *IPv4Net) IsHostRoute() bool {
+	return net.m32.prefixLen == 32
+}
+
+// IsPointToPoint returns true if this is a /31 network, in which both addresses
+// are usable hosts of a point-to-point link (rfc3021).
+func (net *IPv4Net) IsPointToPoint() bool {
+	return net.m32.prefixLen == 31
+}
+
+// IsStrictSubnetOf returns true if this IPv4Net is contained by, but not equal to, other.
+func (net *IPv4Net) IsStrictSubnetOf(other *IPv4Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel == -1
+}
+
+// IsStrictSupernetOf returns true if this IPv4Net contains, but is not equal to, other.
+func (net *IPv4Net) IsStrictSupernetOf(other *IPv4Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel == 1
+}
+
+// IsSubnetOf returns true if this IPv4Net is contained by other. The test is inclusive,
+// so a network is a subnet of itself; use IsStrictSubnetOf to exclude equal networks.
+func (net *IPv4Net) IsSubnetOf(other *IPv4Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel != 1
+}
+
+// IsSupernetOf returns true if this IPv4Net contains other. The test is inclusive,
+// so a network is a supernet of itself; use IsStrictSupernetOf to exclude equal networks.
+func (net *IPv4Net) IsSupernetOf(other *IPv4Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel != -1
+}
+
+// Key returns a comparable value identifying this IPv4Net, suitable for use as a map key.
+// It holds the 4 bytes of the network address followed by the prefix length, so two
+// networks are equal if and only if their keys are equal. Key does not allocate.
+func (net *IPv4Net) Key() [5]byte {
+	addr := net.base.addr
+	return [5]byte{byte(addr >> 24), byte(addr >> 16), byte(addr >> 8), byte(addr), byte(net.m32.prefixLen)}
+}
+
+// LastUsable returns the last usable host address of this network (see Hosts).
+// For /31 networks (rfc3021) and /32 networks this is the last address of the network.
+func (net *IPv4Net) LastUsable() *IPv4 {
+	hosts := net.Hosts()
+	return hosts.At(hosts.Len() - 1)
 }
 
 // Len returns the number of IP addresses in this network.
@@ -161,6 +583,59 @@ func (net *IPv4Net) Len() uint32 {
 	return net.m32.Len()
 }
 
+// LenBig implements IPNet. It returns the number of IP addresses in this network,
+// and unlike Len it does not overflow for 0.0.0.0/0.
+func (net *IPv4Net) LenBig() *big.Int {
+	return new(big.Int).SetUint64(uint64(1) << (32 - net.m32.prefixLen))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the 4 bytes of the
+// network address in big-endian order followed by a single byte holding the prefix length.
+func (net *IPv4Net) MarshalBinary() ([]byte, error) {
+	return append(net.base.Bytes(), byte(net.m32.prefixLen)), nil
+}
+
+// Merge returns the minimal list of networks covering both this IPv4Net and other.
+// Networks which contain one another are reduced to the supernet, and adjacent
+// networks are summarized where possible. Unrelated networks are returned sorted.
+func (net *IPv4Net) Merge(other *IPv4Net) IPv4NetList {
+	if other == nil {
+		return IPv4NetList{net}
+	}
+	return IPv4NetList{net, other}.Summ()
+}
+
+// MissingSubnets returns the minimal list of networks which must be added to the
+// given IPv4NetList in order for it to fully cover this IPv4Net. As with Fill, any
+// entries which are not subnets of this IPv4Net are ignored. If the list contains
+// this IPv4Net (or a supernet of it) then nothing is missing.
+func (net *IPv4Net) MissingSubnets(list IPv4NetList) IPv4NetList {
+	var missing IPv4NetList
+	for _, e := range list {
+		if isRel, rel := net.Rel(e); isRel && rel != 1 {
+			return missing
+		}
+	}
+
+	filled := net.Fill(list)
+	if len(filled) == 0 {
+		return IPv4NetList{net}
+	}
+	for _, e := range filled {
+		found := false
+		for _, l := range list {
+			if cmp, _ := e.Cmp(l); cmp == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
+
 // Netmask returns the Mask32 used by the IPv4Net.
 func (net *IPv4Net) Netmask() *Mask32 {
 	return net.m32
@@ -181,12 +656,53 @@ func (net *IPv4Net) Next() *IPv4Net {
 	return addr.grow()
 }
 
+// NextFree returns the first subnet of the given prefix length, in ascending order,
+// which does not overlap any of the networks within allocated. It will return nil if
+// no such subnet exists, or if prefixLen is shorter than that of this network or > 32.
+func (net *IPv4Net) NextFree(prefixLen uint, allocated IPv4NetList) *IPv4Net {
+	if prefixLen < net.m32.prefixLen || prefixLen > 32 {
+		return nil
+	}
+
+	cand := net.Resize(prefixLen)
+	for cand != nil && net.Contains(cand.base) {
+		var conflict *IPv4Net
+		for _, e := range allocated {
+			if isRel, _ := cand.Rel(e); isRel {
+				conflict = e
+				break
+			}
+		}
+		if conflict == nil {
+			return cand
+		}
+
+		if conflict.m32.prefixLen < prefixLen { // skip past the whole of the allocated supernet
+			next := conflict.NextSib()
+			if next == nil {
+				return nil
+			}
+			cand = &IPv4Net{next.base, cand.m32}
+		} else {
+			cand = cand.NextSib()
+		}
+	}
+	return nil
+}
+
 // NextSib returns the network immediately following this one.
 // It will return nil if the end of the address space is reached.
 func (net *IPv4Net) NextSib() *IPv4Net {
 	return net.nthNextSib(1)
 }
 
+// NextSibN returns the network n positions after this one among networks of the same size
+// (ie. NextSibN(1) is equivalent to NextSib). It will return nil if the end of the address
+// space is exceeded.
+func (net *IPv4Net) NextSibN(n uint32) *IPv4Net {
+	return net.nthNextSib(n)
+}
+
 // Nth returns the IP address at the given index.
 // The size of the network may be determined with the Len() method.
 // If the range is exceeded then return nil.
@@ -198,17 +714,32 @@ func (net *IPv4Net) Nth(index uint32) *IPv4 {
 }
 
 // NthSubnet returns the subnet IPv4Net at the given index.
-// The number of subnets may be determined with the SubnetCount() method.
+// The number of subnets may be determined with the SubnetCount64() method.
 // If the range is exceeded  or an invalid prefixLen is provided then return nil.
 func (net *IPv4Net) NthSubnet(prefixLen uint, index uint32) *IPv4Net {
-	count := net.SubnetCount(prefixLen)
-	if count == 0 || index >= count{
+	count := net.SubnetCount64(prefixLen)
+	if count == 0 || uint64(index) >= count {
 		return nil
 	}
 	sub0 := net.Resize(prefixLen)
 	return sub0.nthNextSib(index)
 }
 
+// Partition returns every subnet of the given prefix length within this IPv4Net in
+// ascending order. It is the eager equivalent of WalkSubnets(). An error is returned if
+// prefixLen is not longer than the prefix of this network, or is longer than 32.
+func (net *IPv4Net) Partition(prefixLen uint) (IPv4NetList, error) {
+	if prefixLen <= net.m32.prefixLen || prefixLen > 32 {
+		return nil, fmt.Errorf("Prefix length %d is invalid. It must be between %d and 32.", prefixLen, net.m32.prefixLen+1)
+	}
+	return net.subnets(prefixLen), nil
+}
+
+// PrefixLen returns the prefix length of the IPv4Net.
+func (net *IPv4Net) PrefixLen() uint {
+	return net.m32.prefixLen
+}
+
 // Prev returns the previous largest consecutive IP network
 // or nil if the start of the address space is reached.
 func (net *IPv4Net) Prev() *IPv4Net {
@@ -219,12 +750,24 @@ func (net *IPv4Net) Prev() *IPv4Net {
 // PrevSib returns the network immediately preceding this one.
 // It will return nil if this is 0.0.0.0.
 func (net *IPv4Net) PrevSib() *IPv4Net {
-	if net.base.addr == 0 {
+	return net.nthPrevSib(1)
+}
+
+// PrevSibN returns the network n positions before this one among networks of the same size
+// (ie. PrevSibN(1) is equivalent to PrevSib). It will return nil if the start of the address
+// space is exceeded.
+func (net *IPv4Net) PrevSibN(n uint32) *IPv4Net {
+	return net.nthPrevSib(n)
+}
+
+// Rebase returns a network with the same prefix length as this one, anchored at newBase.
+// newBase is re-masked to the prefix length, so any host bits it carries are discarded.
+// Returns nil if newBase is nil. See also Resize, which changes the prefix length instead.
+func (net *IPv4Net) Rebase(newBase *IPv4) *IPv4Net {
+	if newBase == nil {
 		return nil
 	}
-	shift := 32 - net.m32.prefixLen
-	addr := (net.base.addr>>shift - 1) << shift
-	return &IPv4Net{NewIPv4(addr), net.m32}
+	return initIPv4Net(newBase, net.m32)
 }
 
 /*
@@ -258,6 +801,22 @@ func (net *IPv4Net) Rel(other *IPv4Net) (bool, int) {
 	return false, 0
 }
 
+// Relationship returns the Relationship of this IPv4Net to other. It provides the same
+// information as Rel() in a single value. A nil other is Unrelated.
+func (net *IPv4Net) Relationship(other *IPv4Net) Relationship {
+	isRel, rel := net.Rel(other)
+	if !isRel {
+		return Unrelated
+	}
+	switch rel {
+	case 1:
+		return Supernet
+	case -1:
+		return Subnet
+	}
+	return Equal
+}
+
 // Resize returns a copy of the network with an adjusted netmask or nil if an invalid prefixLen is given.
 func (net *IPv4Net) Resize(prefixLen uint) *IPv4Net{
 	if prefixLen > 32{
@@ -268,15 +827,145 @@ func (net *IPv4Net) Resize(prefixLen uint) *IPv4Net{
 	return net
 }
 
+// ResizeE is like Resize but returns an error rather than nil if an invalid prefixLen is given.
+func (net *IPv4Net) ResizeE(prefixLen uint) (*IPv4Net, error) {
+	m32, err := NewMask32(prefixLen)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to resize %s. %s", net, err.Error())
+	}
+	return initIPv4Net(net.base, m32), nil
+}
+
+// ResizeForHosts returns a copy of the network resized to the smallest network capable of
+// holding the given number of usable hosts (see Mask32ForHostCount). The network address
+// is re-masked so that it remains aligned to the new prefix length.
+// Returns nil if the number of hosts exceeds the capacity of IPv4.
+func (net *IPv4Net) ResizeForHosts(hosts uint32) *IPv4Net {
+	m32, err := Mask32ForHostCount(hosts)
+	if err != nil {
+		return nil
+	}
+	return initIPv4Net(net.base, m32)
+}
+
+// SampleHosts returns a function which yields every step'th address of this network,
+// beginning with the network address, for use in reachability probes and the like.
+// The function returns false once the broadcast address has been passed.
+// A step of 0 is rejected. See also StepHosts().
+func (net *IPv4Net) SampleHosts(step uint32) (func() (*IPv4, bool), error) {
+	if step == 0 {
+		return nil, fmt.Errorf("Step must be greater than 0.")
+	}
+	iter := net.StepHosts(step)
+	return func() (*IPv4, bool) {
+		ip := iter.Next()
+		return ip, ip != nil
+	}, nil
+}
+
+/*
+Sanity returns warnings for suspicious, though valid, networks which are likely the
+result of misconfigured input. Warnings are produced for networks based on:
+	* a multicast address (224.0.0.0/4)
+	* a loopback address (127.0.0.0/8)
+
+Returns nil if there is nothing to report. Note that since host bits are always
+masked off at construction, input such as 1.2.3.4/0 is indistinguishable from
+0.0.0.0/0 at this point. Use ParseIPv4NetStrict() to detect host bits during parsing.
+*/
+func (net *IPv4Net) Sanity() []string {
+	var warnings []string
+	if net.base.IsMulticast() {
+		warnings = append(warnings, fmt.Sprintf("Network %s is based on a multicast address.", net))
+	}
+	if net.base.IsLoopback() {
+		warnings = append(warnings, fmt.Sprintf("Network %s is based on a loopback address.", net))
+	}
+	return warnings
+}
+
+// Scan implements sql.Scanner. It accepts a network in string or []byte form.
+func (net *IPv4Net) Scan(src interface{}) error {
+	str, err := scanString(src, "IPv4Net")
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseIPv4Net(str)
+	if err != nil {
+		return err
+	}
+	*net = *parsed
+	return nil
+}
+
+// Shrink returns the lower of the two halves of this IPv4Net, ie. the first subnet
+// with a prefix length one longer. It returns nil for /32 networks.
+func (net *IPv4Net) Shrink() *IPv4Net {
+	if net.m32.prefixLen == 32 {
+		return nil
+	}
+	return &IPv4Net{net.base, initMask32(net.m32.prefixLen + 1)}
+}
+
+// Split divides this IPv4Net into count equally sized subnets. count must be a power of 2
+// and the resulting subnets may not be longer than /32.
+func (net *IPv4Net) Split(count uint32) (IPv4NetList, error) {
+	if count == 0 || count&(count-1) != 0 {
+		return nil, fmt.Errorf("Count %d is not a power of 2.", count)
+	}
+	prefixLen := net.m32.prefixLen + uint(bits.TrailingZeros32(count))
+	if prefixLen > 32 {
+		return nil, fmt.Errorf("Network %s cannot be split into %d subnets.", net, count)
+	}
+	return net.subnets(prefixLen), nil
+}
+
+/*
+SplitToMinHosts splits this IPv4Net into equally sized subnets which are as small as
+possible while each still holding at least minHosts usable host addresses. Usable
+addresses exclude the network and broadcast addresses of /30 and shorter networks.
+Returns this IPv4Net alone if it cannot be split any further, or an error if this
+IPv4Net is itself too small. Note that the result is built in full, so splitting a
+very large network into very small subnets will allocate accordingly.
+*/
+func (net *IPv4Net) SplitToMinHosts(minHosts uint32) (IPv4NetList, error) {
+	m32, err := Mask32ForHostCount(minHosts)
+	if err != nil || m32.prefixLen < net.m32.prefixLen {
+		return nil, fmt.Errorf("Network %s is too small to hold %d hosts.", net, minHosts)
+	}
+	return net.subnets(m32.prefixLen), nil
+}
+
+// StepHosts returns an iterator over every step'th address of this network, beginning with
+// the network address (ie. base, base+step, base+2*step ...). This is useful for coarse
+// sampling of large networks. The iterator is empty if step is 0.
+func (net *IPv4Net) StepHosts(step uint32) *IPv4Iter {
+	iter := &IPv4Iter{next: uint64(net.base.addr), step: uint64(step)}
+	if step != 0 {
+		iter.end = uint64(net.base.addr) + uint64(1)<<(32-net.m32.prefixLen)
+	}
+	return iter
+}
+
 // String returns the network address as a string in CIDR format.
 func (net *IPv4Net) String() string {
 	return net.base.String() + net.m32.String()
 }
 
 
+// StringNoHostSuffix behaves like String, but omits the prefix length of /32 networks
+// (eg. 192.168.1.1 rather than 192.168.1.1/32).
+func (net *IPv4Net) StringNoHostSuffix() string {
+	if net.m32.prefixLen == 32 {
+		return net.base.String()
+	}
+	return net.String()
+}
+
 // SubnetCount returns the number a subnets of a given prefix length that this IPv4Net contains.
 // It will return 0 for invalid requests (ie. bad prefix or prefix is shorter than that of this network).
-// It will also return 0 if the result exceeds the capacity of uint32 (ie. if you want the # of /32 a /0 will hold)
+// It will also return 0 if the result exceeds the capacity of uint32 (ie. if you want the # of /32 a /0 will hold).
+// Use SubnetCount64() to avoid this limitation.
 func (net *IPv4Net) SubnetCount(prefixLen uint) uint32 {
 	if prefixLen <= net.m32.prefixLen || prefixLen > 32 {
 		return 0
@@ -284,6 +973,16 @@ func (net *IPv4Net) SubnetCount(prefixLen uint) uint32 {
 	return 1 << (prefixLen - net.m32.prefixLen)
 }
 
+// SubnetCount64 is like SubnetCount, but returns a uint64 which is able to hold the
+// full range of results (eg. a /0 holds 4294967296 /32 subnets). It will still return
+// 0 for invalid requests.
+func (net *IPv4Net) SubnetCount64(prefixLen uint) uint64 {
+	if prefixLen <= net.m32.prefixLen || prefixLen > 32 {
+		return 0
+	}
+	return 1 << (prefixLen - net.m32.prefixLen)
+}
+
 // Summ creates a summary address from this IPv4Net and another or nil if the two networks are incapable of being summarized.
 func (net *IPv4Net) Summ(other *IPv4Net) *IPv4Net {
 	if other == nil || net.m32.prefixLen != other.m32.prefixLen {
@@ -301,42 +1000,85 @@ func (net *IPv4Net) Summ(other *IPv4Net) *IPv4Net {
 	return net.Resize(net.m32.prefixLen - 1)
 }
 
-func (ip *IPv4Net) Version() uint{return 4}
+// Supernet returns the network of the given prefix length which contains this IPv4Net.
+// The network address is re-masked so that the result is properly aligned. An error is
+// returned if prefixLen is longer than the prefix length of this IPv4Net.
+func (net *IPv4Net) Supernet(prefixLen uint) (*IPv4Net, error) {
+	if prefixLen > net.m32.prefixLen {
+		return nil, fmt.Errorf("Prefix length %d is longer than that of network %s.", prefixLen, net)
+	}
+	return initIPv4Net(net.base, initMask32(prefixLen)), nil
+}
 
-// NON EXPORTED
+// TailSubnet returns the last subnet of the given prefix length within this IPv4Net.
+// It is equivalent to NthSubnet(prefixLen, SubnetCount64(prefixLen)-1) and returns nil
+// under the same conditions.
+func (net *IPv4Net) TailSubnet(prefixLen uint) *IPv4Net {
+	count := net.SubnetCount64(prefixLen)
+	if count == 0 {
+		return nil
+	}
+	return net.NthSubnet(prefixLen, uint32(count-1))
+}
 
-// backfill generates subnets between this net and the limit address.
-// limit should be < net. will create subnets up to and including limit.
-func (net *IPv4Net) backfill(limit uint32) IPv4NetList {
-	var nets IPv4NetList
-	cur := net
-	for {
-		prev := cur.Prev()
-		if prev == nil || prev.base.addr < limit {
-			break
-		}
-		nets = append(IPv4NetList{prev}, nets...)
-		cur = prev
+// ToNetipPrefix returns the IPv4Net as a netip.Prefix from the standard library.
+func (net *IPv4Net) ToNetipPrefix() netip.Prefix {
+	return netip.PrefixFrom(net.base.ToNetipAddr(), int(net.m32.prefixLen))
+}
+
+// ToStdIPNet returns the IPv4Net as a net.IPNet from the standard library.
+func (net *IPv4Net) ToStdIPNet() *stdnet.IPNet {
+	return &stdnet.IPNet{IP: net.base.ToStdIP(), Mask: stdnet.CIDRMask(int(net.m32.prefixLen), 32)}
+}
+
+// TryMerge is like Summ but reports whether the two networks could be summarized.
+// The summary network is returned along with true only when this IPv4Net and other
+// are equally sized, adjacent, and together form a properly aligned supernet.
+func (net *IPv4Net) TryMerge(other *IPv4Net) (*IPv4Net, bool) {
+	summ := net.Summ(other)
+	return summ, summ != nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. See MarshalBinary.
+func (net *IPv4Net) UnmarshalBinary(data []byte) error {
+	if len(data) != 5 {
+		return fmt.Errorf("IPv4Net must be exactly 5 bytes. Received %d bytes.", len(data))
 	}
-	return nets
+	ip, _ := IPv4FromBytes(data[:4])
+	m32, err := NewMask32(uint(data[4]))
+	if err != nil {
+		return err
+	}
+	*net = *initIPv4Net(ip, m32)
+	return nil
 }
 
-// fwdFill returns subnets between this net and the limit address.
-// limit should be > net. will create subnets up to limit.
-func (net *IPv4Net) fwdFill(limit uint32) IPv4NetList {
-	var nets IPv4NetList
-	cur := net
-	for {
-		next := cur.Next()
-		if next == nil || next.base.addr >= limit {
-			break
-		}
-		nets = append(nets, next)
-		cur = next
+// UsableCount returns the number of usable host addresses in this network. For /30 and
+// shorter networks this excludes the network and broadcast addresses. A /31 (rfc3021)
+// has 2 usable addresses and a /32 has 1. Use Len() for the total number of addresses.
+func (net *IPv4Net) UsableCount() uint32 {
+	return net.m32.usable()
+}
+
+// Value implements driver.Valuer. The network is stored in CIDR string form.
+func (net *IPv4Net) Value() (driver.Value, error) {
+	return net.String(), nil
+}
+
+func (ip *IPv4Net) Version() uint{return 4}
+
+// WalkSubnets calls fn for each subnet of the given prefix length within this IPv4Net in
+// ascending order, stopping early if fn returns false. Subnets are generated as they are
+// visited rather than all at once. It does nothing if prefixLen is invalid (see SubnetCount).
+func (net *IPv4Net) WalkSubnets(prefixLen uint, fn func(*IPv4Net) bool) {
+	if prefixLen <= net.m32.prefixLen || prefixLen > 32 {
+		return
 	}
-	return nets
+	net.walkSubnets(prefixLen, fn)
 }
 
+// NON EXPORTED
+
 // initIPv4Net initializes a new IPv4Net
 func initIPv4Net(ip *IPv4, m32 *Mask32) *IPv4Net {
 	net := new(IPv4Net)
@@ -362,13 +1104,104 @@ func (net *IPv4Net) grow() *IPv4Net {
 	return &IPv4Net{NewIPv4(addr), initMask32(prefixLen)}
 }
 
+// ipv4RangeNets returns the minimal list of networks covering the addresses from start
+// up to, but not including, end. end may be 1<<32 to include 255.255.255.255.
+func ipv4RangeNets(start, end uint64) IPv4NetList {
+	var nets IPv4NetList
+	for start < end {
+		size := uint64(1) << 32
+		if start != 0 {
+			size = start & -start // largest block aligned on start
+		}
+		for size > end-start {
+			size >>= 1
+		}
+		nets = append(nets, &IPv4Net{NewIPv4(uint32(start)), initMask32(32 - uint(bits.TrailingZeros64(size)))})
+		start += size
+	}
+	return nets
+}
+
 // nthNextSib returns the nth next sibling network or nil if address space exceeded.
 func (net *IPv4Net) nthNextSib(nth uint32) *IPv4Net {
 	shift := 32 - net.m32.prefixLen
-	addr := (net.base.addr>>shift + nth) << shift
-	if addr == 0 { // we exceeded the address space
+	index := uint64(net.base.addr)>>shift + uint64(nth)
+	if index >= uint64(1)<<net.m32.prefixLen { // we exceeded the address space
 		return nil
 	}
-	return &IPv4Net{NewIPv4(addr), net.m32}
+	return &IPv4Net{NewIPv4(uint32(index << shift)), net.m32}
+}
+
+// nthPrevSib returns the nth previous sibling network or nil if address space exceeded.
+func (net *IPv4Net) nthPrevSib(nth uint32) *IPv4Net {
+	shift := 32 - net.m32.prefixLen
+	index := uint64(net.base.addr) >> shift
+	if uint64(nth) > index { // we exceeded the address space
+		return nil
+	}
+	return &IPv4Net{NewIPv4(uint32((index - uint64(nth)) << shift)), net.m32}
+}
+
+// parseIPv4Net parses a string into its IPv4 and Mask32 components without masking
+// the address. The Mask32 will be nil if the string does not contain a netmask.
+func parseIPv4Net(addr string) (*IPv4, *Mask32, error) {
+	addr = strings.TrimSpace(addr)
+	var m32 *Mask32
+
+	// parse out netmask
+	if strings.Contains(addr, "/") { // cidr format
+		addrSplit := strings.Split(addr, "/")
+		if len(addrSplit) > 2 {
+			return nil, nil, fmt.Errorf("IP address contains multiple '/' characters.")
+		}
+		addr = addrSplit[0]
+		prefixLen := addrSplit[1]
+		var err error
+		m32, err = ParseMask32(prefixLen)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if strings.Contains(addr, " ") { // extended format
+		addrSplit := strings.SplitN(addr, " ", 2)
+		addr = addrSplit[0]
+		mask := addrSplit[1]
+		var err error
+		m32, err = ParseMask32(mask)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// parse ip
+	ip, err := ParseIPv4(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ip, m32, nil
 }
 
+// subnets returns every subnet of the given prefix length within this network in ascending order.
+// prefixLen must not be shorter than the prefix length of this network.
+func (net *IPv4Net) subnets(prefixLen uint) IPv4NetList {
+	list := make(IPv4NetList, 0, uint64(1)<<(prefixLen-net.m32.prefixLen))
+	net.walkSubnets(prefixLen, func(sub *IPv4Net) bool {
+		list = append(list, sub)
+		return true
+	})
+	return list
+}
+
+// walkSubnets calls fn for every subnet of the given prefix length within this network in
+// ascending order, stopping early if fn returns false. prefixLen must not be shorter than
+// the prefix length of this network.
+func (net *IPv4Net) walkSubnets(prefixLen uint, fn func(*IPv4Net) bool) {
+	m32 := initMask32(prefixLen)
+	step := uint64(1) << (32 - prefixLen)
+	end := uint64(net.base.addr) + uint64(1)<<(32-net.m32.prefixLen)
+	for addr := uint64(net.base.addr); addr < end; addr += step {
+		if !fn(&IPv4Net{NewIPv4(uint32(addr)), m32}) {
+			return
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package netaddr
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 // IPv4NetList is a slice of IPv4 types
@@ -21,6 +24,188 @@ func NewIPv4NetList(networks []string) (IPv4NetList, error) {
 	return list, nil
 }
 
+/*
+NewIPv4NetListCompressed parses a slice of entries in the form produced by
+IPv4NetList.CompressedStrings() into a IPv4NetList. Each entry is either a single
+network (eg. 10.0.0.0/24) or a network followed by a repeat count (eg. 10.0.0.0/24 x16),
+which expands to that many consecutive networks of the same size.
+*/
+func NewIPv4NetListCompressed(entries []string) (IPv4NetList, error) {
+	var list IPv4NetList
+	for i, e := range entries {
+		fields := strings.Fields(e)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("Error parsing item index %d. Entry '%s' is malformed.", i, e)
+		}
+		net, err := ParseIPv4Net(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing item index %d. %s", i, err.Error())
+		}
+		var count uint64 = 1
+		if len(fields) == 2 {
+			if !strings.HasPrefix(fields[1], "x") {
+				return nil, fmt.Errorf("Error parsing item index %d. Count '%s' must be of the form xN.", i, fields[1])
+			}
+			count, err = strconv.ParseUint(fields[1][1:], 10, 32)
+			if err != nil || count == 0 {
+				return nil, fmt.Errorf("Error parsing item index %d. Count '%s' is invalid.", i, fields[1])
+			}
+		}
+		size := uint64(1) << (32 - net.m32.prefixLen)
+		if uint64(net.base.addr)+count*size > 1<<32 {
+			return nil, fmt.Errorf("Error parsing item index %d. %d networks beginning at %s exceed the address space.",
+				i, count, net)
+		}
+		for n := uint64(0); n < count; n += 1 {
+			list = append(list, &IPv4Net{NewIPv4(net.base.addr + uint32(n*size)), net.m32})
+		}
+	}
+	return list, nil
+}
+
+/*
+ParseIPv4NetList parses a block of text, such as the contents of a blocklist file, into
+a IPv4NetList. Entries are separated by newlines and/or commas and surrounding whitespace
+is ignored, as are blank lines and comments beginning with '#'. Entries which fail to
+parse are skipped and reported in the returned slice of errors, which identify the line
+(counting from 1) of each failure. See ParseIPv4NetListStrict to stop at the first error.
+*/
+func ParseIPv4NetList(s string) (IPv4NetList, []error) {
+	var list IPv4NetList
+	var errs []error
+	parseIPv4NetLines(s, func(line int, entry string) bool {
+		net, err := ParseIPv4Net(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Error parsing line %d. %s", line, err.Error()))
+		} else {
+			list = append(list, net)
+		}
+		return true
+	})
+	return list, errs
+}
+
+// ParseIPv4NetListStrict behaves like ParseIPv4NetList, but returns an error
+// for the first entry which fails to parse instead of skipping it.
+func ParseIPv4NetListStrict(s string) (IPv4NetList, error) {
+	var list IPv4NetList
+	var err error
+	parseIPv4NetLines(s, func(line int, entry string) bool {
+		var net *IPv4Net
+		net, err = ParseIPv4Net(entry)
+		if err != nil {
+			err = fmt.Errorf("Error parsing line %d. %s", line, err.Error())
+			return false
+		}
+		list = append(list, net)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+/*
+Compact returns a sorted copy of the list with duplicate entries removed, along with any
+network which is a subnet of another entry. Unlike Summ(), adjacent networks are not
+merged, so the original sizes of the remaining networks are preserved. The list itself
+is not modified.
+*/
+func (list IPv4NetList) Compact() IPv4NetList {
+	sorted := append(IPv4NetList{}, list...)
+	// order supernets ahead of any subnets which share their network address
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].base.addr != sorted[j].base.addr {
+			return sorted[i].base.addr < sorted[j].base.addr
+		}
+		return sorted[i].m32.prefixLen < sorted[j].m32.prefixLen
+	})
+
+	var compact IPv4NetList
+	for _, e := range sorted {
+		if len(compact) > 0 {
+			last := compact[len(compact)-1]
+			if last.m32.prefixLen <= e.m32.prefixLen && last.Contains(e.base) {
+				continue // duplicate or subnet of last
+			}
+		}
+		compact = append(compact, e)
+	}
+	return compact
+}
+
+/*
+CompressedStrings returns the list as strings with runs of consecutive, equally sized
+networks collapsed into a single entry with a repeat count, eg. 16 consecutive /24
+networks starting at 10.0.0.0 become "10.0.0.0/24 x16". Networks which do not begin a
+run are returned in plain CIDR format. The list should be sorted beforehand since only
+adjacent entries are grouped. NewIPv4NetListCompressed() reverses the conversion.
+*/
+func (list IPv4NetList) CompressedStrings() []string {
+	var strs []string
+	for i := 0; i < len(list); {
+		start := list[i]
+		size := uint64(1) << (32 - start.m32.prefixLen)
+		count := 1
+		for i += 1; i < len(list); i += 1 {
+			e := list[i]
+			if e.m32.prefixLen != start.m32.prefixLen ||
+				uint64(e.base.addr) != uint64(start.base.addr)+uint64(count)*size {
+				break
+			}
+			count += 1
+		}
+		if count == 1 {
+			strs = append(strs, start.String())
+		} else {
+			strs = append(strs, start.String()+" x"+strconv.Itoa(count))
+		}
+	}
+	return strs
+}
+
+/*
+Diff compares this IPv4NetList with other and returns the networks which were
+added (present in other but not in this list) and removed (present in this list
+but not in other). Networks are compared by both network address and prefix length.
+Both returned lists are sorted and neither of the input lists are modified.
+*/
+func (list IPv4NetList) Diff(other IPv4NetList) (added, removed IPv4NetList) {
+	old := append(IPv4NetList{}, list...).Sort()
+	cur := append(IPv4NetList{}, other...).Sort()
+	i, j := 0, 0
+	for i < len(old) && j < len(cur) {
+		cmp, _ := old[i].Cmp(cur[j])
+		if cmp == -1 { // only in old
+			removed = append(removed, old[i])
+			i += 1
+		} else if cmp == 1 { // only in cur
+			added = append(added, cur[j])
+			j += 1
+		} else {
+			i += 1
+			j += 1
+		}
+	}
+	removed = append(removed, old[i:]...)
+	added = append(added, cur[j:]...)
+	return added, removed
+}
+
+// GroupByPrefix returns the networks of the list grouped by prefix length, with the
+// networks of each group sorted (see Sort). The list itself is not modified.
+func (list IPv4NetList) GroupByPrefix() map[uint]IPv4NetList {
+	groups := make(map[uint]IPv4NetList)
+	for _, net := range list {
+		groups[net.m32.prefixLen] = append(groups[net.m32.prefixLen], net)
+	}
+	for _, group := range groups {
+		group.Sort()
+	}
+	return groups
+}
+
 // Len is used to implement the sort interface
 func (list IPv4NetList) Len() int { return len(list) }
 
@@ -30,15 +215,171 @@ func (list IPv4NetList) Less(i, j int) bool {
 	return cmp == -1
 }
 
-// Sort sorts the list using sort.Sort(). Returns itself.
+// LongestPrefix returns the longest prefix length found within the list,
+// or false if the list is empty.
+func (list IPv4NetList) LongestPrefix() (uint, bool) {
+	if len(list) == 0 {
+		return 0, false
+	}
+	longest := list[0].m32.prefixLen
+	for _, net := range list[1:] {
+		if net.m32.prefixLen > longest {
+			longest = net.m32.prefixLen
+		}
+	}
+	return longest, true
+}
+
+// MarshalJSON implements json.Marshaler. The list is encoded as an array of
+// CIDR strings, eg. ["10.0.0.0/8","192.168.0.0/16"].
+func (list IPv4NetList) MarshalJSON() ([]byte, error) {
+	strs := make([]string, len(list))
+	for i, net := range list {
+		strs[i] = net.String()
+	}
+	return json.Marshal(strs)
+}
+
+// MatchAll returns every network in the list which contains ip, ordered from the least
+// specific (shortest prefix) to the most specific. The list itself is not modified.
+func (list IPv4NetList) MatchAll(ip *IPv4) IPv4NetList {
+	var matches IPv4NetList
+	for _, net := range list {
+		if net.Contains(ip) {
+			matches = append(matches, net)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].m32.prefixLen < matches[j].m32.prefixLen
+	})
+	return matches
+}
+
+// Merge returns the union of this list and other as a minimal set of networks covering
+// both (see Summ). Neither of the input lists are modified.
+func (list IPv4NetList) Merge(other IPv4NetList) IPv4NetList {
+	union := make(IPv4NetList, 0, len(list)+len(other))
+	union = append(append(union, list...), other...)
+	return union.Summ()
+}
+
+// Overlapping returns every network in the list which overlaps candidate, ie. which is
+// equal to, a subnet of, or a supernet of it, sorted as per Sort. The list itself is
+// not modified. This is useful for reporting exactly which allocations conflict with
+// a proposed network. An empty list is returned if candidate is nil.
+func (list IPv4NetList) Overlapping(candidate *IPv4Net) IPv4NetList {
+	conflicts := IPv4NetList{}
+	if candidate == nil {
+		return conflicts
+	}
+	for _, net := range list {
+		if isRel, _ := candidate.Rel(net); isRel {
+			conflicts = append(conflicts, net)
+		}
+	}
+	return conflicts.Sort()
+}
+
+// PrefixStats returns a histogram of the prefix lengths found within the list,
+// mapping each prefix length to the number of networks having it.
+func (list IPv4NetList) PrefixStats() map[uint]int {
+	stats := make(map[uint]int)
+	for _, net := range list {
+		stats[net.m32.prefixLen] += 1
+	}
+	return stats
+}
+
+/*
+Ranges merges the networks of the list into the minimal list of contiguous address
+ranges, joining networks which overlap or are adjacent to one another. Unlike Summ,
+the resulting ranges need not be aligned on a bit boundary. The list itself is not
+modified, and the ranges are returned in ascending order. Nil entries are ignored.
+*/
+func (list IPv4NetList) Ranges() []*IPv4Range {
+	var sorted IPv4NetList
+	for _, net := range list {
+		if net != nil {
+			sorted = append(sorted, net)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].base.addr < sorted[j].base.addr
+	})
+	var ranges []*IPv4Range
+	var first, last uint64 // last is exclusive
+	for _, net := range sorted {
+		start := uint64(net.base.addr)
+		end := start + uint64(1)<<(32-net.m32.prefixLen)
+		if last != 0 { // a range is pending
+			if start <= last {
+				if end > last {
+					last = end
+				}
+				continue
+			}
+			ranges = append(ranges, &IPv4Range{NewIPv4(uint32(first)), NewIPv4(uint32(last - 1))})
+		}
+		first, last = start, end
+	}
+	if last != 0 {
+		ranges = append(ranges, &IPv4Range{NewIPv4(uint32(first)), NewIPv4(uint32(last - 1))})
+	}
+	return ranges
+}
+
+// ShortestPrefix returns the shortest prefix length found within the list,
+// or false if the list is empty.
+func (list IPv4NetList) ShortestPrefix() (uint, bool) {
+	if len(list) == 0 {
+		return 0, false
+	}
+	shortest := list[0].m32.prefixLen
+	for _, net := range list[1:] {
+		if net.m32.prefixLen < shortest {
+			shortest = net.m32.prefixLen
+		}
+	}
+	return shortest, true
+}
+
+// Sort sorts the list in place by network address and then netmask (see IPv4Net.Cmp)
+// using sort.Stable(), so equal entries retain their relative order. Returns itself.
 func (list IPv4NetList) Sort() IPv4NetList {
-	sort.Sort(list)
+	sort.Stable(list)
 	return list
 }
 
+/*
+SortBySize returns a sorted copy of the list, leaving the list itself unmodified.
+The copy is ordered by size from smallest network to largest (ie. by descending
+prefix length), with networks of equal size ordered by network address. The sort is
+stable, so equal entries retain their relative order from the list.
+*/
+func (list IPv4NetList) SortBySize() IPv4NetList {
+	sorted := append(IPv4NetList{}, list...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].m32.prefixLen != sorted[j].m32.prefixLen {
+			return sorted[i].m32.prefixLen > sorted[j].m32.prefixLen
+		}
+		return sorted[i].base.addr < sorted[j].base.addr
+	})
+	return sorted
+}
+
 // Summ returns a copy of the list with the contained IPv4Net entries
 // sorted and summarized as much as possible.
 func (list IPv4NetList) Summ() IPv4NetList {
+	return list.SummMax(0)
+}
+
+/*
+SummMax behaves like Summ, but adjacent networks are only merged while the resulting
+summary network has a prefix length of at least minPrefix. This prevents aggregating
+beyond a policy limit (eg. never summarizing into anything larger than a /16). Entries
+which are already larger than minPrefix are kept as is. SummMax(0) is equivalent to Summ.
+*/
+func (list IPv4NetList) SummMax(minPrefix uint) IPv4NetList {
 	var summd IPv4NetList
 	if len(list) > 1 {
 		summd = list.discardSubnets()
@@ -47,7 +388,7 @@ func (list IPv4NetList) Summ() IPv4NetList {
 	}
 
 	if len(summd) > 1 {
-		summd = summd.summPeers()
+		summd = summd.summPeers(minPrefix)
 	}
 	return summd
 }
@@ -55,6 +396,31 @@ func (list IPv4NetList) Summ() IPv4NetList {
 // Swap is used to implement the sort interface
 func (list IPv4NetList) Swap(i, j int) { list[i], list[j] = list[j], list[i] }
 
+// TotalAddresses returns the total number of addresses covered by the networks in the list.
+// Overlapping networks are counted once, since the list is summarized before counting.
+func (list IPv4NetList) TotalAddresses() uint64 {
+	var total uint64
+	for _, net := range list.Summ() {
+		total += uint64(1) << (32 - net.m32.prefixLen)
+	}
+	return total
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts an array of network
+// strings as produced by MarshalJSON and reports the index of the first invalid entry.
+func (list *IPv4NetList) UnmarshalJSON(data []byte) error {
+	var strs []string
+	if err := json.Unmarshal(data, &strs); err != nil {
+		return err
+	}
+	parsed, err := NewIPv4NetList(strs)
+	if err != nil {
+		return err
+	}
+	*list = parsed
+	return nil
+}
+
 // NON EXPORTED
 
 // discardSubnets returns a sorted copy of the IPv4NetList with
@@ -82,9 +448,29 @@ func (list IPv4NetList) discardSubnets() IPv4NetList {
 	return keepers
 }
 
+// parseIPv4NetLines calls fn with the line number and text of each entry of s, as
+// described by ParseIPv4NetList, stopping early if fn returns false.
+func parseIPv4NetLines(s string, fn func(line int, entry string) bool) {
+	for i, line := range strings.Split(s, "\n") {
+		if idx := strings.IndexByte(line, '#'); idx != -1 {
+			line = line[:idx]
+		}
+		for _, entry := range strings.Split(line, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			if !fn(i+1, entry) {
+				return
+			}
+		}
+	}
+}
+
 // summPeers returns a copy of the IPv4NetList with any
-// merge-able subnets Summ'd together.
-func (list IPv4NetList) summPeers() IPv4NetList {
+// merge-able subnets Summ'd together, so long as the summary
+// network has a prefix length of at least minPrefix.
+func (list IPv4NetList) summPeers(minPrefix uint) IPv4NetList {
 	summd := list.Sort()
 	for {
 		listLen := len(summd)
@@ -96,7 +482,10 @@ func (list IPv4NetList) summPeers() IPv4NetList {
 			if i != last {
 				// if we can summarize 2 consecutive entries then store the new
 				// summary net and discard the 2 original networks
-				newNet := net.Summ(summd[next])
+				var newNet *IPv4Net
+				if net.m32.prefixLen > minPrefix {
+					newNet = net.Summ(summd[next])
+				}
 				if newNet != nil { // can summarize. keep summary net
 					tmpList = append(tmpList, newNet)
 					i += 1 // skip over the next entry
@@ -1,7 +1,9 @@
 package netaddr
 
 import "testing"
+import "encoding/json"
 import "fmt"
+import "strings"
 
 func ExampleNewIPv4NetList() {
 	nets := []string{"10.0.0.0/24", "1.0.0.0/24"}
@@ -53,6 +55,438 @@ func Test_NewIPv4NetList(t *testing.T) {
 	}
 }
 
+func Test_NewIPv4NetListCompressed(t *testing.T) {
+	cases := []struct {
+		given  []string
+		expect []string
+	}{
+		{[]string{"10.0.0.0/24 x4"}, []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}},
+		{[]string{"10.0.0.0/24", " 192.168.0.0/31  x2 "}, []string{"10.0.0.0/24", "192.168.0.0/31", "192.168.0.2/31"}},
+		{[]string{"255.255.255.254/32 x2"}, []string{"255.255.255.254/32", "255.255.255.255/32"}},
+		{[]string{"0.0.0.0/1 x2"}, []string{"0.0.0.0/1", "128.0.0.0/1"}},
+		{[]string{}, []string{}},
+	}
+
+	for _, c := range cases {
+		list, err := NewIPv4NetListCompressed(c.given)
+		if err != nil {
+			t.Errorf("NewIPv4NetListCompressed(%v) unexpected error: %s", c.given, err)
+			continue
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("NewIPv4NetListCompressed(%v) Expect: %v  Result: %v", c.given, c.expect, list)
+		}
+	}
+
+	// errors
+	for _, given := range []string{"", "10.0.0.0/24 x", "10.0.0.0/24 x0", "10.0.0.0/24 16", "10.0.0.0/24 xa",
+		"10.0.0.0/24 x1 x2", "10.0.0.0/33", "255.255.255.0/24 x2", "0.0.0.0/0 x2"} {
+		if _, err := NewIPv4NetListCompressed([]string{given}); err == nil {
+			t.Errorf("NewIPv4NetListCompressed(%s) Expect: error  Result: nil", given)
+		}
+	}
+}
+
+func Test_ParseIPv4NetList(t *testing.T) {
+	text := "# blocklist\n10.0.0.0/24, 192.168.1.0/24\r\n\n  1.2.3.4  # single host\n10.0.0.0/33,bogus\n,172.16.0.0/12,\n"
+	list, errs := ParseIPv4NetList(text)
+	expect := []string{"10.0.0.0/24", "192.168.1.0/24", "1.2.3.4/32", "172.16.0.0/12"}
+	if fmt.Sprint(list) != fmt.Sprint(expect) {
+		t.Errorf("ParseIPv4NetList() Expect: %v  Result: %v", expect, list)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("ParseIPv4NetList() Expect: 2 errors  Result: %v", errs)
+	}
+	for _, err := range errs {
+		if !strings.HasPrefix(err.Error(), "Error parsing line 5.") {
+			t.Errorf("ParseIPv4NetList() Expect: error for line 5  Result: %s", err)
+		}
+	}
+
+	if list, errs := ParseIPv4NetList(" \n# nothing here\n"); len(list) != 0 || len(errs) != 0 {
+		t.Errorf("ParseIPv4NetList() Expect: empty results  Result: %v, %v", list, errs)
+	}
+}
+
+func Test_ParseIPv4NetListStrict(t *testing.T) {
+	list, err := ParseIPv4NetListStrict("10.0.0.0/24,192.168.1.0/24 # comment\n1.2.3.4\n")
+	expect := []string{"10.0.0.0/24", "192.168.1.0/24", "1.2.3.4/32"}
+	if err != nil || fmt.Sprint(list) != fmt.Sprint(expect) {
+		t.Errorf("ParseIPv4NetListStrict() Expect: %v  Result: %v, %v", expect, list, err)
+	}
+
+	list, err = ParseIPv4NetListStrict("10.0.0.0/24\n\n10.0.0.0/33\nbogus\n")
+	if list != nil || err == nil || !strings.HasPrefix(err.Error(), "Error parsing line 3.") {
+		t.Errorf("ParseIPv4NetListStrict() Expect: nil and an error for line 3  Result: %v, %v", list, err)
+	}
+}
+
+func Test_IPv4NetList_CompressedStrings(t *testing.T) {
+	// 16 consecutive /24 round-trip
+	var given []string
+	for i := 0; i < 16; i++ {
+		given = append(given, fmt.Sprintf("10.0.%d.0/24", i))
+	}
+	list, _ := NewIPv4NetList(given)
+	compressed := list.CompressedStrings()
+	if fmt.Sprint(compressed) != "[10.0.0.0/24 x16]" {
+		t.Errorf("%v.CompressedStrings() Expect: [10.0.0.0/24 x16]  Result: %v", list, compressed)
+	}
+	expanded, err := NewIPv4NetListCompressed(compressed)
+	if err != nil || fmt.Sprint(expanded) != fmt.Sprint(list) {
+		t.Errorf("NewIPv4NetListCompressed(%v) Expect: %v  Result: %v", compressed, list, expanded)
+	}
+
+	cases := []struct {
+		given  []string
+		expect []string
+	}{
+		{
+			[]string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/25", "10.0.2.128/25", "10.0.4.0/24"},
+			[]string{"10.0.0.0/24 x2", "10.0.2.0/25 x2", "10.0.4.0/24"},
+		},
+		{
+			[]string{"10.0.0.0/24", "10.0.2.0/24", "10.0.3.0/24"},
+			[]string{"10.0.0.0/24", "10.0.2.0/24 x2"},
+		},
+		{
+			[]string{"255.255.255.254/32", "255.255.255.255/32", "0.0.0.0/32"},
+			[]string{"255.255.255.254/32 x2", "0.0.0.0/32"},
+		},
+		{[]string{"10.0.0.0/24"}, []string{"10.0.0.0/24"}},
+		{[]string{}, []string{}},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.given)
+		compressed := list.CompressedStrings()
+		if fmt.Sprint(compressed) != fmt.Sprint(c.expect) {
+			t.Errorf("%v.CompressedStrings() Expect: %v  Result: %v", list, c.expect, compressed)
+		}
+		expanded, _ := NewIPv4NetListCompressed(compressed)
+		if fmt.Sprint(expanded) != fmt.Sprint(list) {
+			t.Errorf("NewIPv4NetListCompressed(%v) Expect: %v  Result: %v", compressed, list, expanded)
+		}
+	}
+}
+
+func Test_IPv4NetList_Compact(t *testing.T) {
+	cases := []struct {
+		given  []string
+		expect []string
+	}{
+		{
+			[]string{"10.0.1.0/24", "10.0.0.0/24", "10.0.1.0/24", "10.0.0.0/24"},
+			[]string{"10.0.0.0/24", "10.0.1.0/24"}, // duplicates removed, adjacents not merged
+		},
+		{
+			[]string{"10.0.0.0/24", "10.0.0.0/8", "10.1.2.0/23", "192.168.0.0/16", "192.168.1.0/24"},
+			[]string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+		{
+			[]string{"10.0.0.0/25", "10.0.0.128/26", "10.0.0.0/24", "10.0.1.0/24"},
+			[]string{"10.0.0.0/24", "10.0.1.0/24"},
+		},
+		{
+			[]string{"10.0.0.0/8", "0.0.0.0/0", "192.168.0.0/16"},
+			[]string{"0.0.0.0/0"},
+		},
+		{[]string{"10.0.0.0/24"}, []string{"10.0.0.0/24"}},
+		{[]string{}, []string{}},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.given)
+		given := fmt.Sprint(list)
+		compact := list.Compact()
+		if fmt.Sprint(compact) != fmt.Sprint(c.expect) {
+			t.Errorf("%v.Compact() Expect: %v  Result: %v", given, c.expect, compact)
+		}
+		if fmt.Sprint(list) != given {
+			t.Errorf("%v.Compact() modified the list: %v", given, list)
+		}
+	}
+}
+
+func Test_IPv4NetList_Diff(t *testing.T) {
+	cases := []struct {
+		list    []string
+		other   []string
+		added   []string
+		removed []string
+	}{
+		{
+			[]string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"},
+			[]string{"10.0.2.0/24", "10.0.0.0/24", "10.0.3.0/24"},
+			[]string{"10.0.3.0/24"},
+			[]string{"10.0.1.0/24"},
+		},
+		{ // same base but different prefix
+			[]string{"10.0.0.0/24"},
+			[]string{"10.0.0.0/25"},
+			[]string{"10.0.0.0/25"},
+			[]string{"10.0.0.0/24"},
+		},
+		{ // identical
+			[]string{"1.0.0.0/8", "2.0.0.0/8"},
+			[]string{"2.0.0.0/8", "1.0.0.0/8"},
+			[]string{},
+			[]string{},
+		},
+		{ // empty other
+			[]string{"2.0.0.0/8", "1.0.0.0/8"},
+			[]string{},
+			[]string{},
+			[]string{"1.0.0.0/8", "2.0.0.0/8"},
+		},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.list)
+		other, _ := NewIPv4NetList(c.other)
+		added, removed := list.Diff(other)
+		if fmt.Sprint(added) != fmt.Sprint(c.added) || fmt.Sprint(removed) != fmt.Sprint(c.removed) {
+			t.Errorf("%v.Diff(%v) Expect: %v,%v  Result: %v,%v", c.list, c.other, c.added, c.removed, added, removed)
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.list) || fmt.Sprint(other) != fmt.Sprint(c.other) {
+			t.Errorf("%v.Diff(%v) modified its inputs. Result: %v,%v", c.list, c.other, list, other)
+		}
+	}
+}
+
+func Test_IPv4NetList_JSON(t *testing.T) {
+	list, _ := NewIPv4NetList([]string{"10.0.0.0/8", "192.168.0.0/16"})
+	b, err := json.Marshal(list)
+	if err != nil || string(b) != `["10.0.0.0/8","192.168.0.0/16"]` {
+		t.Errorf("json.Marshal(%v) Result: %s %v", list, b, err)
+	}
+	var parsed IPv4NetList
+	if err := json.Unmarshal(b, &parsed); err != nil || fmt.Sprint(parsed) != fmt.Sprint(list) {
+		t.Errorf("json.Unmarshal(%s) Expect: %v  Result: %v %v", b, list, parsed, err)
+	}
+
+	// as a struct field
+	var cfg struct {
+		Nets IPv4NetList `json:"nets"`
+	}
+	if err := json.Unmarshal([]byte(`{"nets":["10.0.0.0/8","192.168.0.0/16"]}`), &cfg); err != nil || fmt.Sprint(cfg.Nets) != fmt.Sprint(list) {
+		t.Errorf("json.Unmarshal() Expect: %v  Result: %v %v", list, cfg.Nets, err)
+	}
+
+	// empty
+	b, _ = json.Marshal(IPv4NetList{})
+	if string(b) != "[]" {
+		t.Errorf("json.Marshal(IPv4NetList{}) Expect: []  Result: %s", b)
+	}
+
+	// errors
+	err = json.Unmarshal([]byte(`["10.0.0.0/8","10.0.0.1/33"]`), &parsed)
+	if err == nil || !strings.Contains(err.Error(), "item index 1") {
+		t.Errorf("json.Unmarshal() Expect: error for item index 1  Result: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`"10.0.0.0/8"`), &parsed); err == nil {
+		t.Errorf("json.Unmarshal() Expect: error for non-array  Result: nil")
+	}
+}
+
+func Test_IPv4NetList_GroupByPrefix(t *testing.T) {
+	list, _ := NewIPv4NetList([]string{"10.0.2.0/24", "192.168.0.0/16", "10.0.1.0/24", "10.0.0.1", "172.16.0.0/16", "10.0.0.0/24"})
+	given := fmt.Sprint(list)
+	groups := list.GroupByPrefix()
+	expect := map[uint]string{
+		16: "[172.16.0.0/16 192.168.0.0/16]",
+		24: "[10.0.0.0/24 10.0.1.0/24 10.0.2.0/24]",
+		32: "[10.0.0.1/32]",
+	}
+	if len(groups) != len(expect) {
+		t.Errorf("%s.GroupByPrefix() Expect: %d groups  Result: %v", given, len(expect), groups)
+	}
+	for prefixLen, e := range expect {
+		if fmt.Sprint(groups[prefixLen]) != e {
+			t.Errorf("%s.GroupByPrefix()[%d] Expect: %s  Result: %v", given, prefixLen, e, groups[prefixLen])
+		}
+	}
+	if fmt.Sprint(list) != given {
+		t.Errorf("%s.GroupByPrefix() modified the list: %v", given, list)
+	}
+
+	if groups := (IPv4NetList{}).GroupByPrefix(); len(groups) != 0 {
+		t.Errorf("[].GroupByPrefix() Expect: empty  Result: %v", groups)
+	}
+}
+
+func Test_IPv4NetList_MatchAll(t *testing.T) {
+	list, _ := NewIPv4NetList([]string{"10.0.0.0/24", "192.168.0.0/16", "10.0.0.0/8", "10.0.0.128/25", "0.0.0.0/0", "10.1.0.0/16"})
+	cases := []struct {
+		ip     string
+		expect []string
+	}{
+		{"10.0.0.200", []string{"0.0.0.0/0", "10.0.0.0/8", "10.0.0.0/24", "10.0.0.128/25"}},
+		{"10.0.0.1", []string{"0.0.0.0/0", "10.0.0.0/8", "10.0.0.0/24"}},
+		{"192.168.5.5", []string{"0.0.0.0/0", "192.168.0.0/16"}},
+		{"8.8.8.8", []string{"0.0.0.0/0"}},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.ip)
+		if matches := list.MatchAll(ip); fmt.Sprint(matches) != fmt.Sprint(c.expect) {
+			t.Errorf("%v.MatchAll(%s) Expect: %v  Result: %v", list, c.ip, c.expect, matches)
+		}
+	}
+
+	ip, _ := ParseIPv4("8.8.8.8")
+	if matches := list[:2].MatchAll(ip); len(matches) != 0 {
+		t.Errorf("%v.MatchAll(%s) Expect: []  Result: %v", list[:2], ip, matches)
+	}
+}
+
+func Test_IPv4NetList_Merge(t *testing.T) {
+	cases := []struct {
+		list   []string
+		other  []string
+		expect []string
+	}{
+		{[]string{"10.0.0.0/24"}, []string{"10.0.1.0/24"}, []string{"10.0.0.0/23"}},
+		{[]string{"10.0.0.0/8", "192.168.1.0/24"}, []string{"10.1.0.0/16", "192.168.1.0/24"}, []string{"10.0.0.0/8", "192.168.1.0/24"}},
+		{[]string{"10.0.1.0/24", "10.0.3.0/24"}, []string{"10.0.2.0/24", "10.0.0.0/24"}, []string{"10.0.0.0/22"}},
+		{[]string{"10.0.0.0/24"}, []string{}, []string{"10.0.0.0/24"}},
+		{[]string{}, []string{}, []string{}},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.list)
+		other, _ := NewIPv4NetList(c.other)
+		merged := list.Merge(other)
+		if fmt.Sprint(merged) != fmt.Sprint(c.expect) {
+			t.Errorf("%v.Merge(%v) Expect: %v  Result: %v", c.list, c.other, c.expect, merged)
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.list) || fmt.Sprint(other) != fmt.Sprint(c.other) {
+			t.Errorf("%v.Merge(%v) modified its inputs: %v %v", c.list, c.other, list, other)
+		}
+	}
+}
+
+func Test_IPv4NetList_Overlapping(t *testing.T) {
+	list, _ := NewIPv4NetList([]string{"10.0.1.0/24", "192.168.0.0/16", "10.0.0.128/25", "10.0.0.0/8", "10.0.0.0/24", "10.0.0.0/26"})
+	cases := []struct {
+		candidate string
+		expect    []string
+	}{
+		{"10.0.0.0/25", []string{"10.0.0.0/26", "10.0.0.0/24", "10.0.0.0/8"}},
+		{"10.0.0.0/23", []string{"10.0.0.0/26", "10.0.0.0/24", "10.0.0.0/8", "10.0.0.128/25", "10.0.1.0/24"}},
+		{"192.168.1.1/32", []string{"192.168.0.0/16"}},
+		{"172.16.0.0/12", []string{}},
+	}
+
+	for _, c := range cases {
+		candidate, _ := ParseIPv4Net(c.candidate)
+		if conflicts := list.Overlapping(candidate); fmt.Sprint(conflicts) != fmt.Sprint(c.expect) {
+			t.Errorf("%v.Overlapping(%s) Expect: %v  Result: %v", list, c.candidate, c.expect, conflicts)
+		}
+	}
+
+	if list[0].String() != "10.0.1.0/24" {
+		t.Errorf("Overlapping() modified the list: %v", list)
+	}
+
+	if conflicts := list.Overlapping(nil); conflicts == nil || len(conflicts) != 0 {
+		t.Errorf("%v.Overlapping(nil) Expect: []  Result: %#v", list, conflicts)
+	}
+}
+
+func Test_IPv4NetList_PrefixStats(t *testing.T) {
+	cases := []struct {
+		given    []string
+		stats    string
+		shortest uint
+		longest  uint
+		ok       bool
+	}{
+		{[]string{"10.0.0.0/8", "10.0.0.0/24", "10.0.1.0/24", "10.0.0.1"}, "map[8:1 24:2 32:1]", 8, 32, true},
+		{[]string{"0.0.0.0/0"}, "map[0:1]", 0, 0, true},
+		{[]string{"10.0.0.0/24", "192.168.0.0/24"}, "map[24:2]", 24, 24, true},
+		{[]string{}, "map[]", 0, 0, false},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.given)
+		if stats := list.PrefixStats(); fmt.Sprint(stats) != c.stats {
+			t.Errorf("%v.PrefixStats() Expect: %s  Result: %v", c.given, c.stats, stats)
+		}
+		if shortest, ok := list.ShortestPrefix(); shortest != c.shortest || ok != c.ok {
+			t.Errorf("%v.ShortestPrefix() Expect: %d, %t  Result: %d, %t", c.given, c.shortest, c.ok, shortest, ok)
+		}
+		if longest, ok := list.LongestPrefix(); longest != c.longest || ok != c.ok {
+			t.Errorf("%v.LongestPrefix() Expect: %d, %t  Result: %d, %t", c.given, c.longest, c.ok, longest, ok)
+		}
+	}
+}
+
+func Test_IPv4NetList_Ranges(t *testing.T) {
+	cases := []struct {
+		given  []string
+		expect string
+	}{
+		{[]string{}, "[]"},
+		{[]string{"10.0.0.0/24"}, "[10.0.0.0-10.0.0.255]"},
+		{[]string{"10.0.1.0/24", "10.0.0.0/24", "10.0.2.0/25"}, "[10.0.0.0-10.0.2.127]"},                    // adjacent, not bit-aligned
+		{[]string{"10.0.0.0/16", "10.0.5.0/24", "10.0.255.0/24"}, "[10.0.0.0-10.0.255.255]"},                // contained
+		{[]string{"10.0.0.0/25", "10.0.0.64/26", "10.0.0.96/27", "10.0.0.128/32"}, "[10.0.0.0-10.0.0.128]"}, // overlapping
+		{[]string{"10.0.0.0/24", "10.0.2.0/24", "192.168.0.1/32"}, "[10.0.0.0-10.0.0.255 10.0.2.0-10.0.2.255 192.168.0.1-192.168.0.1]"},
+		{[]string{"255.255.255.255/32", "0.0.0.0/32"}, "[0.0.0.0-0.0.0.0 255.255.255.255-255.255.255.255]"},
+		{[]string{"128.0.0.0/1", "0.0.0.0/1"}, "[0.0.0.0-255.255.255.255]"},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.given)
+		orig := fmt.Sprint(list)
+		if ranges := list.Ranges(); fmt.Sprint(ranges) != c.expect {
+			t.Errorf("%v.Ranges() Expect: %s  Result: %v", c.given, c.expect, ranges)
+		}
+		if fmt.Sprint(list) != orig {
+			t.Errorf("%v.Ranges() modified the list: %v", c.given, list)
+		}
+	}
+
+	// nil entries are ignored
+	list := IPv4NetList{MustParseIPv4Net("10.0.1.0/24"), nil, MustParseIPv4Net("10.0.0.0/24"), nil}
+	if ranges := list.Ranges(); fmt.Sprint(ranges) != "[10.0.0.0-10.0.1.255]" {
+		t.Errorf("%v.Ranges() Expect: [10.0.0.0-10.0.1.255]  Result: %v", list, ranges)
+	}
+	if ranges := (IPv4NetList{nil}).Ranges(); len(ranges) != 0 {
+		t.Errorf("[<nil>].Ranges() Expect: []  Result: %v", ranges)
+	}
+}
+
+func Test_IPv4NetList_Sort_Stable(t *testing.T) {
+	list, _ := NewIPv4NetList([]string{"10.0.0.0/24", "1.0.0.0/8", "10.0.0.0/24"})
+	first, second := list[0], list[2]
+	list.Sort()
+	if list[1] != first || list[2] != second {
+		t.Errorf("Sort() did not preserve the order of equal entries")
+	}
+}
+
+func Test_IPv4NetList_SortBySize(t *testing.T) {
+	given := []string{"10.0.0.0/8", "192.168.1.0/24", "8.8.8.8/32", "10.0.0.0/24", "1.0.0.0/8", "0.0.0.0/0", "9.9.9.9/32"}
+	expect := []string{"8.8.8.8/32", "9.9.9.9/32", "10.0.0.0/24", "192.168.1.0/24", "1.0.0.0/8", "10.0.0.0/8", "0.0.0.0/0"}
+	list, _ := NewIPv4NetList(given)
+	sorted := list.SortBySize()
+	if fmt.Sprint(sorted) != fmt.Sprint(expect) {
+		t.Errorf("%v.SortBySize() Expect: %v  Result: %v", given, expect, sorted)
+	}
+	if fmt.Sprint(list) != fmt.Sprint(given) {
+		t.Errorf("%v.SortBySize() modified the list. Result: %v", given, list)
+	}
+
+	// stable
+	list, _ = NewIPv4NetList([]string{"10.0.0.0/24", "1.0.0.0/8", "10.0.0.0/24"})
+	sorted = list.SortBySize()
+	if sorted[0] != list[0] || sorted[1] != list[2] {
+		t.Errorf("SortBySize() did not preserve the order of equal entries")
+	}
+}
+
 func Test_IPv4NetList_Summ(t *testing.T) {
 	cases := []struct {
 		given  []string
@@ -87,3 +521,60 @@ func Test_IPv4NetList_Summ(t *testing.T) {
 		}
 	}
 }
+
+func Test_IPv4NetList_SummMax(t *testing.T) {
+	cases := []struct {
+		given     []string
+		minPrefix uint
+		expect    []string
+	}{
+		{
+			[]string{"10.0.0.0/29", "10.0.0.8/30", "10.0.0.12/30", "10.0.0.16/28", "10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/25"},
+			26,
+			[]string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/25"},
+		},
+		{ // never aggregate beyond a /16
+			[]string{"10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/24", "10.2.1.0/24"},
+			16,
+			[]string{"10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/23"},
+		},
+		{ // entries already larger than minPrefix are kept
+			[]string{"10.0.0.0/8", "11.0.0.0/8", "12.0.0.0/24"},
+			16,
+			[]string{"10.0.0.0/8", "11.0.0.0/8", "12.0.0.0/24"},
+		},
+		{ // 0 is equivalent to Summ
+			[]string{"0.0.0.0/1", "128.0.0.0/1"},
+			0,
+			[]string{"0.0.0.0/0"},
+		},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.given)
+		if summd := list.SummMax(c.minPrefix); fmt.Sprint(summd) != fmt.Sprint(c.expect) {
+			t.Errorf("%v.SummMax(%d) Expect: %v  Result: %v", c.given, c.minPrefix, c.expect, summd)
+		}
+	}
+}
+
+func Test_IPv4NetList_TotalAddresses(t *testing.T) {
+	cases := []struct {
+		given  []string
+		expect uint64
+	}{
+		{[]string{"10.0.0.0/16", "10.0.1.0/24"}, 65536}, // /24 within the /16 counted once
+		{[]string{"10.0.0.0/24", "10.0.1.0/24", "192.168.0.1"}, 513},
+		{[]string{"10.0.0.0/24", "10.0.0.0/24"}, 256},
+		{[]string{"0.0.0.0/0", "10.0.0.0/8"}, 4294967296},
+		{[]string{"0.0.0.0/1", "128.0.0.0/1"}, 4294967296},
+		{[]string{}, 0},
+	}
+
+	for _, c := range cases {
+		list, _ := NewIPv4NetList(c.given)
+		if total := list.TotalAddresses(); total != c.expect {
+			t.Errorf("%v.TotalAddresses() Expect: %d  Result: %d", c.given, c.expect, total)
+		}
+	}
+}
@@ -1,7 +1,15 @@
 package netaddr
 
 import "testing"
+import "context"
+import "encoding/json"
 import "fmt"
+import "math"
+import "strings"
+import stdnet "net"
+import "net/netip"
+import "sort"
+import "time"
 
 func ExampleParseIPv4Net() {
 	net, _ := ParseIPv4Net("10.0.0.0/24")
@@ -88,6 +96,26 @@ func ExampleIPv4Net_Summ() {
 	// Output: 10.0.0.0/29
 }
 
+// FuzzParseIPv4Net checks that any network accepted by ParseIPv4Net round-trips through String.
+func FuzzParseIPv4Net(f *testing.F) {
+	for _, seed := range []string{"0.0.0.0/0", "10.0.0.0/8", "1.2.3.4/32", "10.1.1.1 255.255.0.0", " 192.168.1.0/24 ", "1.2.3.4", "1.2.3.4/33", "1.2.3.4/"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		net, err := ParseIPv4Net(s)
+		if err != nil {
+			return
+		}
+		reparsed, err := ParseIPv4Net(net.String())
+		if err != nil {
+			t.Fatalf("ParseIPv4Net(%q) = %s which failed to reparse: %s", s, net, err)
+		}
+		if cmp, _ := reparsed.Cmp(net); cmp != 0 || reparsed.String() != net.String() {
+			t.Fatalf("ParseIPv4Net(%q) = %s which reparsed as %s", s, net, reparsed)
+		}
+	})
+}
+
 func Test_ParseIPv4Net(t *testing.T) {
 	cases := []struct {
 		given     string
@@ -118,6 +146,103 @@ func Test_ParseIPv4Net(t *testing.T) {
 	}
 }
 
+func Test_ParseIPv4NetStrict(t *testing.T) {
+	cases := []struct {
+		given     string
+		expect    string
+		expectErr bool
+	}{
+		{"192.168.1.0/24", "192.168.1.0/24", false},
+		{"192.168.1.0 255.255.255.0", "192.168.1.0/24", false},
+		{"192.168.1.5", "192.168.1.5/32", false},
+		{"0.0.0.0/0", "0.0.0.0/0", false},
+		{"192.168.1.5/24", "", true},
+		{"10.0.0.1/8", "", true},
+		{"10.0.0.0/33", "", true},
+	}
+
+	for _, c := range cases {
+		net, err := ParseIPv4NetStrict(c.given)
+		if err != nil {
+			if !c.expectErr {
+				t.Errorf("ParseIPv4NetStrict(%s) unexpected parse error: %s", c.given, err.Error())
+			}
+			continue
+		}
+
+		if c.expectErr {
+			t.Errorf("ParseIPv4NetStrict(%s) expected error but none raised", c.given)
+			continue
+		}
+
+		if net.String() != c.expect {
+			t.Errorf("ParseIPv4NetStrict(%s) Expect: %s  Result: %s", c.given, c.expect, net)
+		}
+	}
+
+	// error should report both the supplied and masked address
+	_, err := ParseIPv4NetStrict("192.168.1.5/24")
+	if err == nil || !strings.Contains(err.Error(), "192.168.1.5") || !strings.Contains(err.Error(), "192.168.1.0") {
+		t.Errorf("ParseIPv4NetStrict(192.168.1.5/24) unexpected error message: %v", err)
+	}
+}
+
+func Test_ParseIPv4NetWithHostBits(t *testing.T) {
+	cases := []struct {
+		given       string
+		expect      string
+		hadHostBits bool
+	}{
+		{"10.0.0.1/8", "10.0.0.0/8", true},
+		{"10.0.0.0/8", "10.0.0.0/8", false},
+		{"192.168.1.129 255.255.255.128", "192.168.1.128/25", true},
+		{"192.168.1.1", "192.168.1.1/32", false},
+		{"1.2.3.4/0", "0.0.0.0/0", true},
+	}
+
+	for _, c := range cases {
+		net, hadHostBits, err := ParseIPv4NetWithHostBits(c.given)
+		if err != nil {
+			t.Errorf("ParseIPv4NetWithHostBits(%s) unexpected error: %s", c.given, err)
+			continue
+		}
+		if net.String() != c.expect || hadHostBits != c.hadHostBits {
+			t.Errorf("ParseIPv4NetWithHostBits(%s) Expect: %s %t  Result: %s %t", c.given, c.expect, c.hadHostBits, net, hadHostBits)
+		}
+	}
+
+	if net, _, err := ParseIPv4NetWithHostBits("10.0.0.0/33"); err == nil {
+		t.Errorf("ParseIPv4NetWithHostBits(10.0.0.0/33) Expect: error  Result: %s", net)
+	}
+}
+
+func Test_MustParseIPv4Net(t *testing.T) {
+	if parsed := MustParseIPv4Net("10.0.0.1/24"); parsed.String() != "10.0.0.0/24" {
+		t.Errorf("MustParseIPv4Net(10.0.0.1/24) Expect: 10.0.0.0/24  Result: %s", parsed)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "10.0.0.0/8/24") {
+			t.Errorf("MustParseIPv4Net(10.0.0.0/8/24) expected a panic naming the input. Result: %v", r)
+		}
+	}()
+	MustParseIPv4Net("10.0.0.0/8/24")
+}
+
+func Test_ParseIPv4NetOrDefault(t *testing.T) {
+	def := MustParseIPv4Net("192.168.0.0/16")
+	if parsed := ParseIPv4NetOrDefault("10.0.0.0/24", def); parsed.String() != "10.0.0.0/24" {
+		t.Errorf("ParseIPv4NetOrDefault(10.0.0.0/24) Expect: 10.0.0.0/24  Result: %s", parsed)
+	}
+	if parsed := ParseIPv4NetOrDefault("10.0.0.0/33", def); parsed != def {
+		t.Errorf("ParseIPv4NetOrDefault(10.0.0.0/33) Expect: %s  Result: %s", def, parsed)
+	}
+	if parsed := ParseIPv4NetOrDefault("", nil); parsed != nil {
+		t.Errorf("ParseIPv4NetOrDefault() Expect: nil  Result: %s", parsed)
+	}
+}
+
 func Test_NewIPv4Net(t *testing.T) {
 	cases := []struct {
 		ip        string
@@ -163,6 +288,181 @@ func Test_NewIPv4Net(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_AdjacentTo(t *testing.T) {
+	cases := []struct {
+		net    string
+		other  string
+		expect bool
+	}{
+		{"10.0.0.0/24", "10.0.1.0/24", true},
+		{"10.0.1.0/24", "10.0.0.0/24", true},
+		{"10.0.1.0/24", "10.0.2.0/24", false}, // adjacent but not aligned siblings
+		{"10.0.0.0/24", "10.0.1.0/25", false},
+		{"10.0.0.0/24", "10.0.0.0/24", false},
+		{"0.0.0.0/1", "128.0.0.0/1", true},
+		{"0.0.0.0/0", "0.0.0.0/0", false},
+		{"10.0.0.0/32", "10.0.0.1/32", true},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		other, _ := ParseIPv4Net(c.other)
+		if res := net.AdjacentTo(other); res != c.expect {
+			t.Errorf("%s.AdjacentTo(%s) Expect: %v  Result: %v", c.net, c.other, c.expect, res)
+		}
+		if c.expect && net.Summ(other) == nil {
+			t.Errorf("%s.Summ(%s) Expect: a summary  Result: nil", c.net, c.other)
+		}
+	}
+
+	if MustParseIPv4Net("10.0.0.0/24").AdjacentTo(nil) {
+		t.Errorf("10.0.0.0/24.AdjacentTo(nil) Expect: false  Result: true")
+	}
+}
+
+func Test_IPv4Net_Align(t *testing.T) {
+	cases := []struct {
+		ip        uint32
+		prefixLen uint
+		expect    string
+	}{
+		{0x0a000001, 8, "10.0.0.0/8"},
+		{0x0a0000ff, 24, "10.0.0.0/24"},
+		{0x0a0000ff, 32, "10.0.0.255/32"},
+		{0xffffffff, 0, "0.0.0.0/0"},
+	}
+
+	for _, c := range cases {
+		m32, _ := NewMask32(c.prefixLen)
+		net, _ := NewIPv4Net(NewIPv4(c.ip), m32)
+		if !net.IsAligned() {
+			t.Errorf("%s.IsAligned() Expect: true  Result: false", net)
+		}
+		aligned := net.Align()
+		if aligned.String() != c.expect || !aligned.IsAligned() {
+			t.Errorf("%s.Align() Expect: %s  Result: %s", net, c.expect, aligned)
+		}
+		if aligned == net {
+			t.Errorf("%s.Align() Expect: a copy  Result: the receiver", net)
+		}
+	}
+}
+
+func Test_IPv4Net_AllHosts(t *testing.T) {
+	cases := []struct {
+		net    string
+		limit  uint32
+		expect []string
+	}{
+		{"10.0.0.0/29", 6, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}},
+		{"10.0.0.0/31", 10, []string{"10.0.0.0", "10.0.0.1"}},
+		{"10.0.0.1/32", 1, []string{"10.0.0.1"}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		list, err := net.AllHosts(c.limit)
+		if err != nil {
+			t.Errorf("%s.AllHosts(%d) unexpected error: %s", c.net, c.limit, err)
+			continue
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.AllHosts(%d) Expect: %v  Result: %v", c.net, c.limit, c.expect, list)
+		}
+	}
+
+	net, _ := ParseIPv4Net("10.0.0.0/28")
+	if list, _ := net.AllHosts(14); len(list) != 14 {
+		t.Errorf("%s.AllHosts(14) Expect: 14 hosts  Result: %d", net, len(list))
+	}
+	if _, err := net.AllHosts(13); err == nil {
+		t.Errorf("%s.AllHosts(13) Expect: error  Result: nil", net)
+	}
+	net, _ = ParseIPv4Net("10.0.0.0/8")
+	if _, err := net.AllHosts(65536); err == nil {
+		t.Errorf("%s.AllHosts(65536) Expect: error  Result: nil", net)
+	}
+}
+
+func Test_IPv4Net_AsDetailJSON(t *testing.T) {
+	cases := []struct {
+		net    string
+		expect IPv4NetDetail
+		json   string
+	}{
+		{
+			"10.0.0.0/24",
+			IPv4NetDetail{"10.0.0.0/24", "10.0.0.0", "10.0.0.255", "255.255.255.0", 254},
+			`{"cidr":"10.0.0.0/24","network":"10.0.0.0","broadcast":"10.0.0.255","netmask":"255.255.255.0","hostCount":254}`,
+		},
+		{
+			"0.0.0.0/0",
+			IPv4NetDetail{"0.0.0.0/0", "0.0.0.0", "255.255.255.255", "0.0.0.0", 4294967294},
+			`{"cidr":"0.0.0.0/0","network":"0.0.0.0","broadcast":"255.255.255.255","netmask":"0.0.0.0","hostCount":4294967294}`,
+		},
+		{
+			"10.0.0.1/32",
+			IPv4NetDetail{"10.0.0.1/32", "10.0.0.1", "10.0.0.1", "255.255.255.255", 1},
+			`{"cidr":"10.0.0.1/32","network":"10.0.0.1","broadcast":"10.0.0.1","netmask":"255.255.255.255","hostCount":1}`,
+		},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		detail := net.AsDetailJSON()
+		if detail != c.expect {
+			t.Errorf("%s.AsDetailJSON() Expect: %+v  Result: %+v", c.net, c.expect, detail)
+		}
+		b, _ := json.Marshal(detail)
+		if string(b) != c.json {
+			t.Errorf("%s.AsDetailJSON() Expect: %s  Result: %s", c.net, c.json, b)
+		}
+	}
+}
+
+func Test_IPv4Net_BitBreakdown(t *testing.T) {
+	cases := []struct {
+		net      string
+		ip       string // "" for nil
+		netBits  string
+		hostBits string
+	}{
+		{"192.168.1.128/25", "192.168.1.130", "1100000010101000000000011", "0000010"},
+		{"192.168.1.128/25", "", "1100000010101000000000011", "0000000"},
+		{"10.0.0.0/8", "10.1.2.3", "00001010", "000000010000001000000011"},
+		{"0.0.0.0/0", "128.0.0.1", "", "10000000000000000000000000000001"},
+		{"255.255.255.255/32", "255.255.255.255", "11111111111111111111111111111111", ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		var ip *IPv4
+		if c.ip != "" {
+			ip, _ = ParseIPv4(c.ip)
+		}
+		netBits, hostBits := net.BitBreakdown(ip)
+		if netBits != c.netBits || hostBits != c.hostBits {
+			t.Errorf("%s.BitBreakdown(%s) Expect: %s,%s  Result: %s,%s", c.net, c.ip, c.netBits, c.hostBits, netBits, hostBits)
+		}
+	}
+}
+
+func Test_IPv4Net_Clone(t *testing.T) {
+	net, _ := ParseIPv4Net("10.0.0.0/24")
+	clone := net.Clone()
+	if clone == net || clone.base == net.base || clone.m32 == net.m32 {
+		t.Errorf("%s.Clone() Expect: no shared pointers", net)
+	}
+	if cmp, _ := clone.Cmp(net); cmp != 0 || clone.Netmask().Extended() != "255.255.255.0" {
+		t.Errorf("%s.Clone() Expect: %s  Result: %s", net, net, clone)
+	}
+	clone.base.addr = 0
+	clone.m32.prefixLen = 8
+	if net.String() != "10.0.0.0/24" {
+		t.Errorf("%s.Clone() modification of the copy changed the original", net)
+	}
+}
+
 func Test_IPv4Net_Cmp(t *testing.T) {
 	cases := []struct {
 		ip1 string
@@ -186,6 +486,66 @@ func Test_IPv4Net_Cmp(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_CmpNet(t *testing.T) {
+	cases := []struct {
+		net1 *IPv4Net
+		net2 *IPv4Net
+		res  int
+	}{
+		{MustParseIPv4Net("1.1.1.0/24"), MustParseIPv4Net("1.1.2.0/24"), -1},
+		{MustParseIPv4Net("1.1.1.0/24"), MustParseIPv4Net("1.1.1.0/25"), 1},
+		{MustParseIPv4Net("1.1.1.0/24"), MustParseIPv4Net("1.1.1.0/24"), 0},
+		{MustParseIPv4Net("0.0.0.0/0"), nil, 1}, // nil is least
+		{nil, MustParseIPv4Net("0.0.0.0/0"), -1},
+		{nil, nil, 0},
+	}
+
+	for _, c := range cases {
+		if res := c.net1.CmpNet(c.net2); res != c.res {
+			t.Errorf("%v.CmpNet(%v) Expect: %d  Result: %d", c.net1, c.net2, c.res, res)
+		}
+	}
+
+	list := IPv4NetList{MustParseIPv4Net("10.0.0.0/24"), nil, MustParseIPv4Net("10.0.0.0/25"), MustParseIPv4Net("1.0.0.0/8")}
+	sort.Slice(list, func(i, j int) bool { return list[i].CmpNet(list[j]) < 0 })
+	if list[0] != nil || fmt.Sprint(list[1:]) != "[1.0.0.0/8 10.0.0.0/25 10.0.0.0/24]" {
+		t.Errorf("sort.Slice using CmpNet Expect: [<nil> 1.0.0.0/8 10.0.0.0/25 10.0.0.0/24]  Result: %v", list)
+	}
+}
+
+func Test_IPv4Net_CommonSupernet(t *testing.T) {
+	cases := []struct {
+		net    string
+		other  string
+		expect string
+	}{
+		{"10.0.0.0/24", "10.0.1.0/24", "10.0.0.0/23"},
+		{"10.0.0.0/24", "10.0.3.0/24", "10.0.0.0/22"},
+		{"10.0.1.0/24", "10.0.2.0/24", "10.0.0.0/22"}, // adjacent but not mergeable by Summ
+		{"10.0.0.0/24", "10.0.0.128/25", "10.0.0.0/24"},
+		{"10.0.0.128/25", "10.0.0.0/16", "10.0.0.0/16"},
+		{"10.0.0.0/24", "10.0.0.0/24", "10.0.0.0/24"},
+		{"10.0.0.1/32", "10.0.0.2/32", "10.0.0.0/30"},
+		{"10.0.0.0/8", "192.168.0.0/16", "0.0.0.0/0"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		other, _ := ParseIPv4Net(c.other)
+		if supernet := net.CommonSupernet(other); supernet.String() != c.expect {
+			t.Errorf("%s.CommonSupernet(%s) Expect: %s  Result: %s", c.net, c.other, c.expect, supernet)
+		}
+		if supernet := other.CommonSupernet(net); supernet.String() != c.expect {
+			t.Errorf("%s.CommonSupernet(%s) Expect: %s  Result: %s", c.other, c.net, c.expect, supernet)
+		}
+	}
+
+	net, _ := ParseIPv4Net("10.0.0.0/24")
+	if supernet := net.CommonSupernet(nil); supernet != nil {
+		t.Errorf("%s.CommonSupernet(nil) Expect: nil  Result: %s", net, supernet)
+	}
+}
+
 func Test_IPv4Net_Contains(t *testing.T) {
 	cases := []struct {
 		net    string
@@ -206,6 +566,122 @@ func Test_IPv4Net_Contains(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_ContainsAll_ContainsAny(t *testing.T) {
+	cases := []struct {
+		net         string
+		ips         []string
+		containsAll bool
+		containsAny bool
+	}{
+		{"10.0.0.0/8", []string{"10.0.0.1", "10.255.255.255"}, true, true},
+		{"10.0.0.0/8", []string{"10.0.0.1", "11.0.0.1"}, false, true},
+		{"10.0.0.0/8", []string{"9.255.255.255", "11.0.0.1"}, false, false},
+		{"10.0.0.0/8", []string{}, true, false},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		ips, _ := NewIPv4List(c.ips)
+		if net.ContainsAll(ips) != c.containsAll {
+			t.Errorf("%s.ContainsAll(%v) Expect: %t  Result: %t", c.net, c.ips, c.containsAll, !c.containsAll)
+		}
+		if net.ContainsAny(ips) != c.containsAny {
+			t.Errorf("%s.ContainsAny(%v) Expect: %t  Result: %t", c.net, c.ips, c.containsAny, !c.containsAny)
+		}
+	}
+}
+
+func Test_IPv4Net_Decompose(t *testing.T) {
+	cases := []struct {
+		net    string
+		ip     string
+		levels []uint
+		expect []uint32
+	}{
+		{"10.0.0.0/24", "10.0.0.130", []uint{26}, []uint32{2, 2}},
+		{"10.0.0.0/24", "10.0.0.0", []uint{26}, []uint32{0, 0}},
+		{"10.0.0.0/24", "10.0.0.255", []uint{26}, []uint32{3, 63}},
+		{"10.0.0.0/16", "10.0.5.67", []uint{20, 24, 28}, []uint32{0, 5, 4, 3}},
+		{"10.0.0.0/16", "10.0.5.67", nil, []uint32{1347}},
+		{"10.0.0.0/24", "10.0.0.7", []uint{32}, []uint32{7, 0}},
+		{"0.0.0.0/0", "255.255.255.255", []uint{32}, []uint32{4294967295, 0}},
+		{"0.0.0.0/0", "255.255.255.255", nil, []uint32{4294967295}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		ip, _ := ParseIPv4(c.ip)
+		components, err := net.Decompose(ip, c.levels)
+		if err != nil {
+			t.Errorf("%s.Decompose(%s, %v) unexpected error: %s", c.net, c.ip, c.levels, err)
+			continue
+		}
+		if fmt.Sprint(components) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.Decompose(%s, %v) Expect: %v  Result: %v", c.net, c.ip, c.levels, c.expect, components)
+		}
+	}
+
+	// errors
+	errCases := []struct {
+		net    string
+		ip     string
+		levels []uint
+	}{
+		{"10.0.0.0/24", "10.0.1.0", []uint{26}},     // not contained
+		{"10.0.0.0/24", "10.0.0.1", []uint{24}},     // not longer than net
+		{"10.0.0.0/24", "10.0.0.1", []uint{28, 26}}, // not ascending
+		{"10.0.0.0/24", "10.0.0.1", []uint{33}},     // too long
+	}
+	for _, c := range errCases {
+		net, _ := ParseIPv4Net(c.net)
+		ip, _ := ParseIPv4(c.ip)
+		if _, err := net.Decompose(ip, c.levels); err == nil {
+			t.Errorf("%s.Decompose(%s, %v) Expect: error  Result: nil", c.net, c.ip, c.levels)
+		}
+	}
+	net, _ := ParseIPv4Net("10.0.0.0/24")
+	if _, err := net.Decompose(nil, nil); err == nil {
+		t.Errorf("%s.Decompose(nil, nil) Expect: error  Result: nil", net)
+	}
+}
+
+func Test_IPv4Net_EqualCIDRString(t *testing.T) {
+	cases := []struct {
+		net    string
+		given  string
+		expect bool
+	}{
+		{"192.168.1.0/24", "192.168.1.0/24", true},
+		{"192.168.1.0/24", " 192.168.1.0/24 ", true},
+		{"192.168.1.0/24", "192.168.1.5/24", true}, // host bits are masked
+		{"192.168.1.1/32", "192.168.1.1", true},
+		{"0.0.0.0/0", "0.0.0.0/0", true},
+		{"192.168.1.0/24", "192.168.1.0/25", false},
+		{"192.168.1.0/24", "192.168.2.0/24", false},
+		{"192.168.1.0/24", "192.168.1.0", false},
+		{"192.168.1.0/24", "192.168.1.0/33", false},
+		{"192.168.1.0/24", "192.168.1.0/", false},
+		{"192.168.1.0/24", "192.168.256.0/24", false},
+		{"192.168.1.0/24", "192.168.1/24", false},
+		{"192.168.1.0/24", "192.168.1.0.0/24", false},
+		{"192.168.1.0/24", "a.b.c.d/24", false},
+		{"192.168.1.0/24", "", false},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		if res := net.EqualCIDRString(c.given); res != c.expect {
+			t.Errorf("%s.EqualCIDRString(%s) Expect: %t  Result: %t", c.net, c.given, c.expect, res)
+		}
+	}
+
+	net, _ := ParseIPv4Net("192.168.1.0/24")
+	allocs := testing.AllocsPerRun(100, func() { net.EqualCIDRString("192.168.1.0/24") })
+	if allocs != 0 {
+		t.Errorf("%s.EqualCIDRString() Expect: 0 allocations  Result: %v", net, allocs)
+	}
+}
+
 func Test_IPv4Net_Fill(t *testing.T) {
 	cases := []struct {
 		net    string
@@ -227,6 +703,26 @@ func Test_IPv4Net_Fill(t *testing.T) {
 			[]string{"1.0.0.0/30", "1.0.0.64/26"},
 			[]string{"1.0.0.0/30", "1.0.0.4/30", "1.0.0.8/29", "1.0.0.16/28", "1.0.0.32/27", "1.0.0.64/26"},
 		},
+		{ // no subnets
+			"10.0.0.0/24",
+			[]string{"10.1.0.0/24"},
+			[]string{},
+		},
+		{ // gap which is not aligned on its own size
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/26", "10.0.0.96/27"},
+			[]string{"10.0.0.0/26", "10.0.0.64/27", "10.0.0.96/27", "10.0.0.128/25"},
+		},
+		{ // end of the address space
+			"0.0.0.0/0",
+			[]string{"128.0.0.0/2", "255.255.255.255/32"},
+			[]string{"0.0.0.0/1", "128.0.0.0/2", "192.0.0.0/3", "224.0.0.0/4", "240.0.0.0/5", "248.0.0.0/6", "252.0.0.0/7",
+				"254.0.0.0/8", "255.0.0.0/9", "255.128.0.0/10", "255.192.0.0/11", "255.224.0.0/12", "255.240.0.0/13",
+				"255.248.0.0/14", "255.252.0.0/15", "255.254.0.0/16", "255.255.0.0/17", "255.255.128.0/18", "255.255.192.0/19",
+				"255.255.224.0/20", "255.255.240.0/21", "255.255.248.0/22", "255.255.252.0/23", "255.255.254.0/24",
+				"255.255.255.0/25", "255.255.255.128/26", "255.255.255.192/27", "255.255.255.224/28", "255.255.255.240/29",
+				"255.255.255.248/30", "255.255.255.252/31", "255.255.255.254/32", "255.255.255.255/32"},
+		},
 	}
 
 	for _, c := range cases {
@@ -246,33 +742,530 @@ func Test_IPv4Net_Fill(t *testing.T) {
 	}
 }
 
-func Test_IPv4Net_Len(t *testing.T) {
+func Test_IPv4Net_FillExact(t *testing.T) {
 	cases := []struct {
-		net string
-		n   uint32
+		net    string
+		subs   []string
+		filled []string
+		err    bool
 	}{
-		{"1.0.0.64", 1},
-		{"1.0.0.64/26", 64},
-		{"1.0.0.0/24", 256},
-		{"1.0.0.0/0", 0},
+		{
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/26"},
+			[]string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/25"},
+			false,
+		},
+		{ // stray network
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/26", "10.0.1.0/26"},
+			nil,
+			true,
+		},
+		{ // the network itself is not a subnet
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/24"},
+			nil,
+			true,
+		},
 	}
 
 	for _, c := range cases {
 		net, _ := ParseIPv4Net(c.net)
-		if net.Len() != c.n {
-			t.Errorf("%s.Len() Expect: %d  Result: %d", net, c.n, net.Len())
+		list, _ := NewIPv4NetList(c.subs)
+		filled, err := net.FillExact(list)
+		if err != nil {
+			if !c.err {
+				t.Errorf("%s.FillExact(%v) unexpected error: %s", c.net, c.subs, err.Error())
+			}
+			continue
+		} else if c.err {
+			t.Errorf("%s.FillExact(%v) expected error but none raised", c.net, c.subs)
+			continue
+		}
+		if fmt.Sprint(filled) != fmt.Sprint(c.filled) {
+			t.Errorf("%s.FillExact(%v) Expect: %v  Result: %v", c.net, c.subs, c.filled, filled)
 		}
 	}
 }
 
-func Test_IPv4Net_Next(t *testing.T) {
+func Test_IPv4Net_FreeSpace(t *testing.T) {
 	cases := []struct {
-		net  string
-		next string
-		end  bool
+		net       string
+		allocated []string
+		free      []string
 	}{
-		{"1.0.0.0/31", "1.0.0.2/31", false},
-		{"1.0.0.4/30", "1.0.0.8/29", false},
+		{
+			"10.0.0.0/24",
+			[]string{"10.0.0.8/30", "10.0.0.16/30", "10.0.0.16/29", "10.1.0.0/24"},
+			[]string{"10.0.0.0/29", "10.0.0.12/30", "10.0.0.24/29", "10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/25"},
+		},
+		{
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/26", "10.0.0.96/27"},
+			[]string{"10.0.0.64/27", "10.0.0.128/25"},
+		},
+		{
+			"1.0.0.0/25",
+			[]string{"1.0.0.0/25"},
+			[]string{},
+		},
+		{ // nothing allocated
+			"10.0.0.0/24",
+			[]string{},
+			[]string{"10.0.0.0/24"},
+		},
+		{
+			"0.0.0.0/0",
+			[]string{"0.0.0.0/1", "255.255.255.255/32"},
+			[]string{"128.0.0.0/2", "192.0.0.0/3", "224.0.0.0/4", "240.0.0.0/5", "248.0.0.0/6", "252.0.0.0/7", "254.0.0.0/8",
+				"255.0.0.0/9", "255.128.0.0/10", "255.192.0.0/11", "255.224.0.0/12", "255.240.0.0/13", "255.248.0.0/14",
+				"255.252.0.0/15", "255.254.0.0/16", "255.255.0.0/17", "255.255.128.0/18", "255.255.192.0/19", "255.255.224.0/20",
+				"255.255.240.0/21", "255.255.248.0/22", "255.255.252.0/23", "255.255.254.0/24", "255.255.255.0/25",
+				"255.255.255.128/26", "255.255.255.192/27", "255.255.255.224/28", "255.255.255.240/29", "255.255.255.248/30",
+				"255.255.255.252/31", "255.255.255.254/32"},
+		},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		list, _ := NewIPv4NetList(c.allocated)
+		free := net.FreeSpace(list)
+		if len(free) != len(c.free) {
+			t.Errorf("%s.FreeSpace(%v) Expect: %v  Result: %v", c.net, c.allocated, c.free, free)
+			continue
+		}
+		for i, e := range c.free {
+			if e != free[i].String() {
+				t.Errorf("%s.FreeSpace(%v) Expect: %v  Result: %v", c.net, c.allocated, c.free, free)
+				break
+			}
+		}
+	}
+}
+
+func Test_IPv4Net_Format(t *testing.T) {
+	cases := []struct {
+		format string
+		expect string
+	}{
+		{"%s", "192.168.1.0/24"},
+		{"%v", "192.168.1.0/24"},
+		{"%-16s|", "192.168.1.0/24  |"},
+		{"%#v", "IPv4Net{base:192.168.1.0 netmask:255.255.255.0 prefixLen:24}"},
+	}
+
+	net, _ := ParseIPv4Net("192.168.1.0/24")
+	for _, c := range cases {
+		if res := fmt.Sprintf(c.format, net); res != c.expect {
+			t.Errorf("Sprintf(%s, %s) Expect: %s  Result: %s", c.format, net, c.expect, res)
+		}
+	}
+}
+
+func Test_IPv4Net_Grow(t *testing.T) {
+	cases := []struct {
+		net    string
+		expect string
+	}{
+		{"10.0.0.0/24", "10.0.0.0/7"},
+		{"10.0.1.0/24", "10.0.1.0/24"},
+		{"10.0.2.0/24", "10.0.2.0/23"},
+		{"10.0.0.1/32", "10.0.0.1/32"},
+		{"0.0.0.0/32", "0.0.0.0/0"},
+		{"0.0.0.0/0", "0.0.0.0/0"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		if grown := net.Grow(); grown.String() != c.expect {
+			t.Errorf("%s.Grow() Expect: %s  Result: %s", c.net, c.expect, grown)
+		}
+	}
+}
+
+func Test_IPv4Net_HeadTailSubnet(t *testing.T) {
+	cases := []struct {
+		given  string
+		prefix uint
+		head   string
+		tail   string
+	}{
+		{"192.168.1.0/24", 28, "192.168.1.0/28", "192.168.1.240/28"},
+		{"192.168.1.0/24", 25, "192.168.1.0/25", "192.168.1.128/25"},
+		{"255.255.255.0/24", 32, "255.255.255.0/32", "255.255.255.255/32"},
+		{"0.0.0.0/0", 32, "0.0.0.0/32", "255.255.255.255/32"},
+		{"0.0.0.0/0", 1, "0.0.0.0/1", "128.0.0.0/1"},
+		{"192.168.1.0/24", 24, "", ""},
+		{"192.168.1.0/24", 33, "", ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.given)
+		head := net.HeadSubnet(c.prefix)
+		if head == nil {
+			if c.head != "" {
+				t.Errorf("%s.HeadSubnet(%d) Expect: %s  Result: nil", c.given, c.prefix, c.head)
+			}
+		} else if head.String() != c.head {
+			t.Errorf("%s.HeadSubnet(%d) Expect: %s  Result: %s", c.given, c.prefix, c.head, head)
+		}
+
+		tail := net.TailSubnet(c.prefix)
+		if tail == nil {
+			if c.tail != "" {
+				t.Errorf("%s.TailSubnet(%d) Expect: %s  Result: nil", c.given, c.prefix, c.tail)
+			}
+		} else if tail.String() != c.tail {
+			t.Errorf("%s.TailSubnet(%d) Expect: %s  Result: %s", c.given, c.prefix, c.tail, tail)
+		}
+	}
+}
+
+func Test_IPv4Net_HostAtPercent(t *testing.T) {
+	cases := []struct {
+		net    string
+		p      float64
+		expect string
+	}{
+		{"10.0.0.0/24", 0.0, "10.0.0.0"},
+		{"10.0.0.0/24", 1.0, "10.0.0.255"},
+		{"10.0.0.0/24", 0.5, "10.0.0.128"},
+		{"10.0.0.0/24", 0.25, "10.0.0.64"},
+		{"10.0.0.0/24", -0.5, "10.0.0.0"},
+		{"10.0.0.0/24", 1.5, "10.0.0.255"},
+		{"10.0.0.0/24", math.NaN(), "10.0.0.0"},
+		{"10.0.0.1/32", 0.5, "10.0.0.1"},
+		{"0.0.0.0/0", 1.0, "255.255.255.255"},
+		{"0.0.0.0/0", 0.5, "128.0.0.0"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		if ip := net.HostAtPercent(c.p); ip.String() != c.expect {
+			t.Errorf("%s.HostAtPercent(%v) Expect: %s  Result: %s", c.net, c.p, c.expect, ip)
+		}
+	}
+}
+
+// Test_IPv4Net_HostMethods checks that every host related method agrees on the
+// handling of the smallest networks, in particular /31 (rfc3021).
+func Test_IPv4Net_HostMethods(t *testing.T) {
+	cases := []struct {
+		net   string
+		len   uint32
+		hosts []string
+		p2p   bool
+	}{
+		{"10.0.0.0/29", 8, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}, false},
+		{"10.0.0.0/30", 4, []string{"10.0.0.1", "10.0.0.2"}, false},
+		{"10.0.0.0/31", 2, []string{"10.0.0.0", "10.0.0.1"}, true},
+		{"10.0.0.1/32", 1, []string{"10.0.0.1"}, false},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		count := uint32(len(c.hosts))
+		if l := net.Len(); l != c.len {
+			t.Errorf("%s.Len() Expect: %d  Result: %d", c.net, c.len, l)
+		}
+		if ip := net.Nth(c.len - 1); ip == nil || net.Nth(c.len) != nil {
+			t.Errorf("%s.Nth() Expect: a range of %d addresses", c.net, c.len)
+		}
+		if usable := net.UsableCount(); usable != count {
+			t.Errorf("%s.UsableCount() Expect: %d  Result: %d", c.net, count, usable)
+		}
+		if hostCount := net.AsDetailJSON().HostCount; hostCount != count {
+			t.Errorf("%s.AsDetailJSON().HostCount Expect: %d  Result: %d", c.net, count, hostCount)
+		}
+		if first := net.FirstUsable(); first.String() != c.hosts[0] {
+			t.Errorf("%s.FirstUsable() Expect: %s  Result: %s", c.net, c.hosts[0], first)
+		}
+		if last := net.LastUsable(); last.String() != c.hosts[count-1] {
+			t.Errorf("%s.LastUsable() Expect: %s  Result: %s", c.net, c.hosts[count-1], last)
+		}
+		if p2p := net.IsPointToPoint(); p2p != c.p2p {
+			t.Errorf("%s.IsPointToPoint() Expect: %v  Result: %v", c.net, c.p2p, p2p)
+		}
+		list, err := net.AllHosts(count)
+		if err != nil || len(list) != len(c.hosts) {
+			t.Errorf("%s.AllHosts(%d) Expect: %v  Result: %v, %v", c.net, count, c.hosts, list, err)
+			continue
+		}
+		for i, e := range c.hosts {
+			if list[i].String() != e {
+				t.Errorf("%s.AllHosts(%d) Expect: %v  Result: %v", c.net, count, c.hosts, list)
+				break
+			}
+		}
+	}
+}
+
+func Test_IPv4Net_Hosts(t *testing.T) {
+	cases := []struct {
+		net   string
+		len   uint32
+		first string
+		last  string
+	}{
+		{"10.0.0.0/24", 254, "10.0.0.1", "10.0.0.254"},
+		{"10.0.0.0/30", 2, "10.0.0.1", "10.0.0.2"},
+		{"10.0.0.0/31", 2, "10.0.0.0", "10.0.0.1"},
+		{"10.0.0.1/32", 1, "10.0.0.1", "10.0.0.1"},
+		{"0.0.0.0/0", 4294967294, "0.0.0.1", "255.255.255.254"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		hosts := net.Hosts()
+		if hosts.Len() != c.len {
+			t.Errorf("%s.Hosts().Len() Expect: %d  Result: %d", c.net, c.len, hosts.Len())
+			continue
+		}
+		if ip := hosts.At(0); ip.String() != c.first {
+			t.Errorf("%s.Hosts().At(0) Expect: %s  Result: %s", c.net, c.first, ip)
+		}
+		if ip := hosts.At(c.len - 1); ip.String() != c.last {
+			t.Errorf("%s.Hosts().At(%d) Expect: %s  Result: %s", c.net, c.len-1, c.last, ip)
+		}
+		if ip := hosts.At(c.len); ip != nil {
+			t.Errorf("%s.Hosts().At(%d) Expect: nil  Result: %s", c.net, c.len, ip)
+		}
+	}
+}
+
+func Test_IPv4Net_HostsContext(t *testing.T) {
+	net, _ := ParseIPv4Net("10.0.0.0/29")
+	var hosts []string
+	for ip := range net.HostsContext(context.Background()) {
+		hosts = append(hosts, ip.String())
+	}
+	expect := "[10.0.0.1 10.0.0.2 10.0.0.3 10.0.0.4 10.0.0.5 10.0.0.6]"
+	if fmt.Sprint(hosts) != expect {
+		t.Errorf("%s.HostsContext() Expect: %s  Result: %v", net, expect, hosts)
+	}
+
+	// stopping early and cancelling must close the channel
+	net, _ = ParseIPv4Net("10.0.0.0/8")
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := net.HostsContext(ctx)
+	for i := 0; i < 3; i += 1 {
+		<-ch
+	}
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for received := 0; ; received += 1 {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			if received > 0 { // at most one send may already be in progress
+				t.Fatalf("%s.HostsContext() Expect: channel closed after cancel  Result: still sending", net)
+			}
+		case <-timeout:
+			t.Fatalf("%s.HostsContext() Expect: channel closed after cancel  Result: timed out", net)
+		}
+	}
+}
+
+func Test_IPv4Net_IsHostRoute_IsDefaultRoute(t *testing.T) {
+	cases := []struct {
+		net          string
+		hostRoute    bool
+		defaultRoute bool
+	}{
+		{"0.0.0.0/0", false, true},
+		{"10.0.0.1/32", true, false},
+		{"10.0.0.1", true, false},
+		{"10.0.0.0/31", false, false},
+		{"0.0.0.0/1", false, false},
+		{"0.0.0.0/32", true, false},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		if net.IsHostRoute() != c.hostRoute {
+			t.Errorf("%s.IsHostRoute() Expect: %t  Result: %t", c.net, c.hostRoute, !c.hostRoute)
+		}
+		if net.IsDefaultRoute() != c.defaultRoute {
+			t.Errorf("%s.IsDefaultRoute() Expect: %t  Result: %t", c.net, c.defaultRoute, !c.defaultRoute)
+		}
+	}
+}
+
+func Test_IPv4Net_IsSubnetOf_IsSupernetOf(t *testing.T) {
+	cases := []struct {
+		net         string
+		other       string
+		subnet      bool
+		strictSub   bool
+		supernet    bool
+		strictSuper bool
+	}{
+		{"10.0.0.0/24", "10.0.0.0/8", true, true, false, false},
+		{"10.0.0.0/8", "10.0.0.0/24", false, false, true, true},
+		{"10.0.0.0/24", "10.0.0.0/24", true, false, true, false},
+		{"10.0.0.0/24", "10.0.1.0/24", false, false, false, false},
+		{"0.0.0.0/0", "255.255.255.255/32", false, false, true, true},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		other, _ := ParseIPv4Net(c.other)
+		if res := net.IsSubnetOf(other); res != c.subnet {
+			t.Errorf("%s.IsSubnetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.subnet, res)
+		}
+		if res := net.IsStrictSubnetOf(other); res != c.strictSub {
+			t.Errorf("%s.IsStrictSubnetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.strictSub, res)
+		}
+		if res := net.IsSupernetOf(other); res != c.supernet {
+			t.Errorf("%s.IsSupernetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.supernet, res)
+		}
+		if res := net.IsStrictSupernetOf(other); res != c.strictSuper {
+			t.Errorf("%s.IsStrictSupernetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.strictSuper, res)
+		}
+	}
+
+	net, _ := ParseIPv4Net(cases[0].net)
+	if net.IsSubnetOf(nil) || net.IsSupernetOf(nil) {
+		t.Errorf("%s.IsSubnetOf(nil) and IsSupernetOf(nil) Expect: false", net)
+	}
+}
+
+func Test_IPv4Net_Key(t *testing.T) {
+	cases := []struct {
+		net1  string
+		net2  string
+		equal bool
+	}{
+		{"10.0.0.0/24", "10.0.0.1/24", true},
+		{"10.0.0.0/24", "10.0.0.0 255.255.255.0", true},
+		{"10.0.0.0/24", "10.0.0.0/25", false},
+		{"10.0.0.0/24", "10.0.1.0/24", false},
+		{"0.0.0.0/0", "0.0.0.0/32", false},
+	}
+
+	for _, c := range cases {
+		net1, _ := ParseIPv4Net(c.net1)
+		net2, _ := ParseIPv4Net(c.net2)
+		if equal := net1.Key() == net2.Key(); equal != c.equal {
+			t.Errorf("%s.Key() == %s.Key() Expect: %v  Result: %v", c.net1, c.net2, c.equal, equal)
+		}
+	}
+
+	net := MustParseIPv4Net("192.168.1.0/24")
+	if key := net.Key(); key != [5]byte{192, 168, 1, 0, 24} {
+		t.Errorf("%s.Key() Expect: [192 168 1 0 24]  Result: %v", net, key)
+	}
+}
+
+func Test_IPv4Net_Len(t *testing.T) {
+	cases := []struct {
+		net string
+		n   uint32
+	}{
+		{"1.0.0.64", 1},
+		{"1.0.0.64/26", 64},
+		{"1.0.0.0/24", 256},
+		{"1.0.0.0/0", 0},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		if net.Len() != c.n {
+			t.Errorf("%s.Len() Expect: %d  Result: %d", net, c.n, net.Len())
+		}
+	}
+}
+
+func Test_IPv4Net_Merge(t *testing.T) {
+	cases := []struct {
+		net    string
+		other  string
+		expect []string
+	}{
+		{"10.0.0.0/24", "10.0.0.0/23", []string{"10.0.0.0/23"}},               // contained
+		{"10.0.0.0/23", "10.0.1.0/24", []string{"10.0.0.0/23"}},               // contains
+		{"10.0.0.0/24", "10.0.0.0/24", []string{"10.0.0.0/24"}},               // equal
+		{"10.0.1.0/24", "10.0.0.0/24", []string{"10.0.0.0/23"}},               // adjacent
+		{"10.0.5.0/24", "10.0.0.0/24", []string{"10.0.0.0/24", "10.0.5.0/24"}}, // disjoint
+		{"10.0.1.0/24", "10.0.2.0/24", []string{"10.0.1.0/24", "10.0.2.0/24"}}, // consecutive but unaligned
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		other, _ := ParseIPv4Net(c.other)
+		merged := net.Merge(other)
+		if fmt.Sprint(merged) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.Merge(%s) Expect: %v  Result: %v", c.net, c.other, c.expect, merged)
+		}
+	}
+}
+
+func Test_IPv4Net_MissingSubnets(t *testing.T) {
+	cases := []struct {
+		net     string
+		subs    []string
+		missing []string
+	}{
+		{
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/26"},
+			[]string{"10.0.0.64/26", "10.0.0.128/25"},
+		},
+		{
+			"10.0.0.0/24",
+			[]string{"10.0.0.8/30", "10.0.0.16/29", "192.168.0.0/24"},
+			[]string{"10.0.0.0/29", "10.0.0.12/30", "10.0.0.24/29", "10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/25"},
+		},
+		{ // nothing allocated
+			"10.0.0.0/24",
+			[]string{"10.1.0.0/24"},
+			[]string{"10.0.0.0/24"},
+		},
+		{ // fully covered
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/25", "10.0.0.128/25"},
+			[]string{},
+		},
+		{ // covered by a supernet
+			"10.0.0.0/24",
+			[]string{"10.0.0.0/16"},
+			[]string{},
+		},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		list, _ := NewIPv4NetList(c.subs)
+		missing := net.MissingSubnets(list)
+		if fmt.Sprint(missing) != fmt.Sprint(c.missing) {
+			t.Errorf("%s.MissingSubnets(%v) Expect: %v  Result: %v", c.net, c.subs, c.missing, missing)
+			continue
+		}
+
+		// list + missing must fully cover the network
+		covered := append(list, missing...).Summ()
+		found := false
+		for _, e := range covered {
+			if isRel, rel := e.Rel(net); isRel && rel >= 0 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s.MissingSubnets(%v) does not provide full coverage. Result: %v", c.net, c.subs, covered)
+		}
+	}
+}
+
+func Test_IPv4Net_Next(t *testing.T) {
+	cases := []struct {
+		net  string
+		next string
+		end  bool
+	}{
+		{"1.0.0.0/31", "1.0.0.2/31", false},
+		{"1.0.0.4/30", "1.0.0.8/29", false},
 		{"1.0.0.8/29", "1.0.0.16/28", false},
 		{"1.0.0.32/27", "1.0.0.64/26", false},
 		{"255.255.255.128/25", "", true},
@@ -295,6 +1288,40 @@ func Test_IPv4Net_Next(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_NextFree(t *testing.T) {
+	cases := []struct {
+		net       string
+		prefix    uint
+		allocated []string
+		expect    string
+	}{
+		{"10.0.0.0/16", 28, []string{}, "10.0.0.0/28"},
+		{"10.0.0.0/16", 28, []string{"10.0.0.0/28", "10.0.0.16/28"}, "10.0.0.32/28"},
+		{"10.0.0.0/16", 28, []string{"10.0.0.0/24", "10.0.1.0/30"}, "10.0.1.16/28"},
+		{"10.0.0.0/16", 24, []string{"10.0.0.0/28", "10.0.1.128/25"}, "10.0.2.0/24"},
+		{"10.0.0.0/16", 28, []string{"172.16.0.0/12", "10.0.0.0/28"}, "10.0.0.16/28"},
+		{"10.0.0.0/24", 26, []string{"10.0.0.0/25", "10.0.0.128/26", "10.0.0.192/26"}, ""}, // full
+		{"10.0.0.0/24", 26, []string{"10.0.0.0/8"}, ""},                                     // allocated supernet
+		{"255.255.255.0/24", 25, []string{"255.255.255.0/25", "255.255.255.128/25"}, ""},    // end of address space
+		{"10.0.0.0/24", 24, []string{}, "10.0.0.0/24"},
+		{"10.0.0.0/24", 23, []string{}, ""},
+		{"10.0.0.0/24", 33, []string{}, ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		allocated, _ := NewIPv4NetList(c.allocated)
+		free := net.NextFree(c.prefix, allocated)
+		if free == nil {
+			if c.expect != "" {
+				t.Errorf("%s.NextFree(%d,%v) Expect: %s  Result: nil", c.net, c.prefix, c.allocated, c.expect)
+			}
+		} else if free.String() != c.expect {
+			t.Errorf("%s.NextFree(%d,%v) Expect: %s  Result: %s", c.net, c.prefix, c.allocated, c.expect, free)
+		}
+	}
+}
+
 func Test_IPv4Net_NextSib(t *testing.T) {
 	cases := []struct {
 		net  string
@@ -324,6 +1351,43 @@ func Test_IPv4Net_NextSib(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_NextSibN_PrevSibN(t *testing.T) {
+	cases := []struct {
+		net  string
+		n    uint32
+		next string
+		prev string
+	}{
+		{"10.0.0.0/24", 0, "10.0.0.0/24", "10.0.0.0/24"},
+		{"10.0.0.0/24", 1, "10.0.1.0/24", "9.255.255.0/24"},
+		{"10.0.0.0/24", 256, "10.1.0.0/24", "9.255.0.0/24"},
+		{"10.0.0.0/8", 245, "255.0.0.0/8", ""},
+		{"10.0.0.0/8", 246, "", ""},
+		{"0.0.0.0/24", 1, "0.0.1.0/24", ""},
+		{"255.255.255.255/32", 1, "", "255.255.255.254/32"},
+		{"255.255.255.255/32", 0xffffffff, "", "0.0.0.0/32"},
+		{"0.0.0.1/32", 0xffffffff, "", ""}, // would wrap around the address space
+		{"0.0.0.0/0", 1, "", ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		var next, prev string // empty when nil
+		if sib := net.NextSibN(c.n); sib != nil {
+			next = sib.String()
+		}
+		if sib := net.PrevSibN(c.n); sib != nil {
+			prev = sib.String()
+		}
+		if next != c.next {
+			t.Errorf("%s.NextSibN(%d) Expect: %s  Result: %s", c.net, c.n, c.next, next)
+		}
+		if prev != c.prev {
+			t.Errorf("%s.PrevSibN(%d) Expect: %s  Result: %s", c.net, c.n, c.prev, prev)
+		}
+	}
+}
+
 func Test_IPv4Net_Nth(t *testing.T) {
 	cases := []struct {
 		given  string
@@ -359,6 +1423,9 @@ func Test_IPv4Net_NthSubnet(t *testing.T) {
 	}{
 		{"192.168.1.0/24", 30, 0, "192.168.1.0/30"},
 		{"192.168.1.0/24", 26, 4, ""},
+		{"0.0.0.0/0", 32, 0, "0.0.0.0/32"},
+		{"0.0.0.0/0", 32, 0xffffffff, "255.255.255.255/32"},
+		{"0.0.0.0/0", 31, 0x80000000, ""},
 	}
 
 	for _, c := range cases {
@@ -458,7 +1525,93 @@ func Test_IPv4Net_Rel(t *testing.T) {
 	}
 }
 
-func Test_IPv4Net_Resize(t *testing.T) {
+func Test_IPv4Net_Partition(t *testing.T) {
+	cases := []struct {
+		net       string
+		prefixLen uint
+		expect    []string
+	}{
+		{"10.0.0.0/24", 26, []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}},
+		{"10.0.0.0/24", 25, []string{"10.0.0.0/25", "10.0.0.128/25"}},
+		{"255.255.255.252/30", 32, []string{"255.255.255.252/32", "255.255.255.253/32", "255.255.255.254/32", "255.255.255.255/32"}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		list, err := net.Partition(c.prefixLen)
+		if err != nil {
+			t.Errorf("%s.Partition(%d) unexpected error: %s", c.net, c.prefixLen, err)
+			continue
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.Partition(%d) Expect: %v  Result: %v", c.net, c.prefixLen, c.expect, list)
+		}
+	}
+
+	net, _ := ParseIPv4Net("10.0.0.0/24")
+	for _, prefixLen := range []uint{0, 23, 24, 33} {
+		if _, err := net.Partition(prefixLen); err == nil {
+			t.Errorf("%s.Partition(%d) Expect: error  Result: nil", net, prefixLen)
+		}
+	}
+}
+
+func Test_IPv4Net_Relationship(t *testing.T) {
+	cases := []struct {
+		net1   string
+		net2   string
+		expect Relationship
+	}{
+		{"1.1.1.0/24", "1.1.1.128/25", Supernet},
+		{"1.1.1.128/25", "1.1.1.0/24", Subnet},
+		{"1.1.1.0/24", "1.1.1.0/24", Equal},
+		{"1.1.1.128/25", "1.1.1.0/25", Unrelated},
+	}
+
+	for _, c := range cases {
+		net1, _ := ParseIPv4Net(c.net1)
+		net2, _ := ParseIPv4Net(c.net2)
+		if res := net1.Relationship(net2); res != c.expect {
+			t.Errorf("%s.Relationship(%s) Expect: %s  Result: %s", c.net1, c.net2, c.expect, res)
+		}
+	}
+
+	net, _ := ParseIPv4Net("1.1.1.0/24")
+	if res := net.Relationship(nil); res != Unrelated {
+		t.Errorf("%s.Relationship(nil) Expect: %s  Result: %s", net, Unrelated, res)
+	}
+}
+
+func Test_IPv4Net_Rebase(t *testing.T) {
+	cases := []struct {
+		net    string
+		base   string
+		expect string
+	}{
+		{"0.0.0.0/24", "10.1.2.0", "10.1.2.0/24"},
+		{"0.0.0.0/24", "10.1.2.77", "10.1.2.0/24"}, // host bits are masked off
+		{"192.168.0.0/16", "172.16.99.1", "172.16.0.0/16"},
+		{"10.0.0.1/32", "10.0.0.2", "10.0.0.2/32"},
+		{"0.0.0.0/0", "192.168.1.1", "0.0.0.0/0"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		if rebased := net.Rebase(MustParseIPv4(c.base)); rebased.String() != c.expect {
+			t.Errorf("%s.Rebase(%s) Expect: %s  Result: %s", c.net, c.base, c.expect, rebased)
+		}
+		if net.String() != c.net {
+			t.Errorf("%s.Rebase(%s) modified the original network: %s", c.net, c.base, net)
+		}
+	}
+
+	net, _ := ParseIPv4Net("10.0.0.0/24")
+	if rebased := net.Rebase(nil); rebased != nil {
+		t.Errorf("%s.Rebase(nil) Expect: nil  Result: %s", net, rebased)
+	}
+}
+
+func Test_IPv4Net_Resize(t *testing.T) {
 	cases := []struct {
 		net    string
 		m      uint
@@ -477,6 +1630,261 @@ func Test_IPv4Net_Resize(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_ResizeE(t *testing.T) {
+	cases := []struct {
+		net       string
+		prefixLen uint
+		expect    string
+	}{
+		{"10.0.0.0/24", 8, "10.0.0.0/8"},
+		{"10.0.0.0/8", 24, "10.0.0.0/24"},
+		{"10.0.0.0/24", 32, "10.0.0.0/32"},
+		{"10.0.0.0/24", 0, "0.0.0.0/0"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		resized, err := net.ResizeE(c.prefixLen)
+		if err != nil {
+			t.Errorf("%s.ResizeE(%d) unexpected error: %s", c.net, c.prefixLen, err)
+			continue
+		}
+		if resized.String() != c.expect {
+			t.Errorf("%s.ResizeE(%d) Expect: %s  Result: %s", c.net, c.prefixLen, c.expect, resized)
+		}
+	}
+
+	net, _ := ParseIPv4Net("10.0.0.0/24")
+	if resized, err := net.ResizeE(33); err == nil || resized != nil {
+		t.Errorf("%s.ResizeE(33) Expect: error  Result: %v", net, resized)
+	}
+}
+
+func Test_IPv4Net_ResizeForHosts(t *testing.T) {
+	cases := []struct {
+		net    string
+		hosts  uint32
+		expect string
+	}{
+		{"10.0.1.0/24", 500, "10.0.0.0/23"},
+		{"10.0.1.0/24", 50, "10.0.1.0/26"},
+		{"10.0.1.0/24", 0, "10.0.1.0/32"},
+		{"10.0.1.0/24", 4294967295, ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		resized := net.ResizeForHosts(c.hosts)
+		if resized == nil {
+			if c.expect != "" {
+				t.Errorf("%s.ResizeForHosts(%d) Expect: %s  Result: nil", c.net, c.hosts, c.expect)
+			}
+		} else if resized.String() != c.expect {
+			t.Errorf("%s.ResizeForHosts(%d) Expect: %s  Result: %s", c.net, c.hosts, c.expect, resized)
+		}
+	}
+}
+
+func Test_IPv4Net_SampleHosts(t *testing.T) {
+	cases := []struct {
+		net    string
+		step   uint32
+		expect []string
+	}{
+		{"10.0.0.0/26", 10, []string{"10.0.0.0", "10.0.0.10", "10.0.0.20", "10.0.0.30", "10.0.0.40", "10.0.0.50", "10.0.0.60"}},
+		{"10.0.0.0/30", 1, []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"255.255.255.252/30", 3, []string{"255.255.255.252", "255.255.255.255"}},
+		{"10.0.0.1/32", 10, []string{"10.0.0.1"}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		next, err := net.SampleHosts(c.step)
+		if err != nil {
+			t.Errorf("%s.SampleHosts(%d) unexpected error: %s", c.net, c.step, err)
+			continue
+		}
+		var ips IPv4List
+		for ip, ok := next(); ok; ip, ok = next() {
+			ips = append(ips, ip)
+		}
+		if fmt.Sprint(ips) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.SampleHosts(%d) Expect: %v  Result: %v", c.net, c.step, c.expect, ips)
+		}
+		if ip, ok := next(); ok || ip != nil {
+			t.Errorf("%s.SampleHosts(%d) Expect: nil, false after exhaustion  Result: %s, %t", c.net, c.step, ip, ok)
+		}
+	}
+
+	// every 10th address of a /16
+	net, _ := ParseIPv4Net("10.1.0.0/16")
+	next, _ := net.SampleHosts(10)
+	count := 0
+	for _, ok := next(); ok; _, ok = next() {
+		count++
+	}
+	if count != 6554 {
+		t.Errorf("%s.SampleHosts(10) Expect: 6554 addresses  Result: %d", net, count)
+	}
+
+	if _, err := net.SampleHosts(0); err == nil {
+		t.Errorf("%s.SampleHosts(0) Expect: error  Result: nil", net)
+	}
+}
+
+func Test_IPv4Net_Sanity(t *testing.T) {
+	cases := []struct {
+		net    string
+		expect []string
+	}{
+		{"127.0.0.0/8", []string{"Network 127.0.0.0/8 is based on a loopback address."}},
+		{"127.0.0.1", []string{"Network 127.0.0.1/32 is based on a loopback address."}},
+		{"224.0.0.0/24", []string{"Network 224.0.0.0/24 is based on a multicast address."}},
+		{"10.0.0.0/8", nil},
+		{"0.0.0.0/0", nil},
+		{"0.0.0.0/1", nil},   // contains loopback but is not based on it
+		{"128.0.0.0/1", nil}, // contains multicast but is not based on it
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		warnings := net.Sanity()
+		if fmt.Sprint(warnings) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.Sanity() Expect: %v  Result: %v", c.net, c.expect, warnings)
+		}
+	}
+}
+
+func Test_IPv4Net_Shrink(t *testing.T) {
+	cases := []struct {
+		net    string
+		expect string
+	}{
+		{"10.0.0.0/24", "10.0.0.0/25"},
+		{"0.0.0.0/0", "0.0.0.0/1"},
+		{"10.0.0.2/31", "10.0.0.2/32"},
+		{"10.0.0.1/32", ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		shrunk := net.Shrink()
+		if shrunk == nil {
+			if c.expect != "" {
+				t.Errorf("%s.Shrink() Expect: %s  Result: nil", c.net, c.expect)
+			}
+		} else if shrunk.String() != c.expect {
+			t.Errorf("%s.Shrink() Expect: %s  Result: %s", c.net, c.expect, shrunk)
+		}
+	}
+}
+
+func Test_IPv4Net_Split(t *testing.T) {
+	cases := []struct {
+		net    string
+		count  uint32
+		expect []string
+	}{
+		{"10.0.0.0/24", 1, []string{"10.0.0.0/24"}},
+		{"10.0.0.0/24", 4, []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}},
+		{"10.0.0.0/30", 4, []string{"10.0.0.0/32", "10.0.0.1/32", "10.0.0.2/32", "10.0.0.3/32"}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		list, err := net.Split(c.count)
+		if err != nil {
+			t.Errorf("%s.Split(%d) unexpected error: %s", c.net, c.count, err)
+			continue
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.Split(%d) Expect: %v  Result: %v", c.net, c.count, c.expect, list)
+		}
+	}
+
+	// errors
+	net, _ := ParseIPv4Net("10.0.0.0/30")
+	for _, count := range []uint32{0, 3, 6, 8} {
+		if _, err := net.Split(count); err == nil {
+			t.Errorf("%s.Split(%d) Expect: error  Result: nil", net, count)
+		}
+	}
+}
+
+func Test_IPv4Net_SplitToMinHosts(t *testing.T) {
+	cases := []struct {
+		net      string
+		minHosts uint32
+		expect   []string
+		err      bool
+	}{
+		{"192.168.1.0/24", 50, []string{"192.168.1.0/26", "192.168.1.64/26", "192.168.1.128/26", "192.168.1.192/26"}, false},
+		{"192.168.1.0/24", 62, []string{"192.168.1.0/26", "192.168.1.64/26", "192.168.1.128/26", "192.168.1.192/26"}, false},
+		{"192.168.1.0/24", 63, []string{"192.168.1.0/25", "192.168.1.128/25"}, false},
+		{"192.168.1.0/24", 254, []string{"192.168.1.0/24"}, false},
+		{"192.168.1.0/30", 1, []string{"192.168.1.0/32", "192.168.1.1/32", "192.168.1.2/32", "192.168.1.3/32"}, false},
+		{"192.168.1.0/30", 2, []string{"192.168.1.0/31", "192.168.1.2/31"}, false},
+		{"255.255.255.0/24", 100, []string{"255.255.255.0/25", "255.255.255.128/25"}, false},
+		{"192.168.1.0/24", 255, nil, true},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		list, err := net.SplitToMinHosts(c.minHosts)
+		if err != nil {
+			if !c.err {
+				t.Errorf("%s.SplitToMinHosts(%d) unexpected error: %s", c.net, c.minHosts, err.Error())
+			}
+			continue
+		} else if c.err {
+			t.Errorf("%s.SplitToMinHosts(%d) expected error but none raised", c.net, c.minHosts)
+			continue
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.SplitToMinHosts(%d) Expect: %v  Result: %v", c.net, c.minHosts, c.expect, list)
+		}
+	}
+}
+
+func Test_IPv4Net_StepHosts(t *testing.T) {
+	cases := []struct {
+		net   string
+		step  uint32
+		count int
+		first string
+		last  string
+	}{
+		{"10.0.0.0/24", 16, 16, "10.0.0.0", "10.0.0.240"},
+		{"10.0.0.0/24", 1, 256, "10.0.0.0", "10.0.0.255"},
+		{"10.0.0.0/24", 100, 3, "10.0.0.0", "10.0.0.200"},
+		{"10.0.0.0/24", 256, 1, "10.0.0.0", "10.0.0.0"},
+		{"10.0.0.0/24", 1000, 1, "10.0.0.0", "10.0.0.0"},
+		{"10.0.0.1/32", 1, 1, "10.0.0.1", "10.0.0.1"},
+		{"255.255.255.0/24", 128, 2, "255.255.255.0", "255.255.255.128"},
+		{"0.0.0.0/0", 1 << 30, 4, "0.0.0.0", "192.0.0.0"},
+		{"10.0.0.0/24", 0, 0, "", ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		iter := net.StepHosts(c.step)
+		var ips IPv4List
+		for ip := iter.Next(); ip != nil; ip = iter.Next() {
+			ips = append(ips, ip)
+		}
+		if len(ips) != c.count {
+			t.Errorf("%s.StepHosts(%d) Expect: %d addresses  Result: %d", c.net, c.step, c.count, len(ips))
+			continue
+		}
+		if c.count > 0 && (ips[0].String() != c.first || ips[len(ips)-1].String() != c.last) {
+			t.Errorf("%s.StepHosts(%d) Expect: %s..%s  Result: %s..%s", c.net, c.step, c.first, c.last, ips[0], ips[len(ips)-1])
+		}
+		if iter.Next() != nil {
+			t.Errorf("%s.StepHosts(%d) Expect: nil after exhaustion", c.net, c.step)
+		}
+	}
+}
+
 func Test_IPv4Net_String(t *testing.T) {
 	cases := []struct {
 		given  string
@@ -496,6 +1904,26 @@ func Test_IPv4Net_String(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_StringNoHostSuffix(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"0.0.0.0", "0.0.0.0"},
+		{"192.168.1.1/32", "192.168.1.1"},
+		{"192.168.1.0/31", "192.168.1.0/31"},
+		{"10.1.1.1/8", "10.0.0.0/8"},
+		{"0.0.0.0/0", "0.0.0.0/0"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.given)
+		if str := net.StringNoHostSuffix(); str != c.expect {
+			t.Errorf("%s.StringNoHostSuffix() Expect: %s  Result: %s", c.given, c.expect, str)
+		}
+	}
+}
+
 func Test_IPv4Net_SubnetCount(t *testing.T) {
 	cases := []struct {
 		net    string
@@ -519,6 +1947,30 @@ func Test_IPv4Net_SubnetCount(t *testing.T) {
 	}
 }
 
+func Test_IPv4Net_SubnetCount64(t *testing.T) {
+	cases := []struct {
+		net    string
+		prefix uint
+		expect uint64
+	}{
+		{"10.0.0.0/24", 25, 2},
+		{"10.0.0.0/24", 30, 64},
+		{"10.0.0.0/24", 24, 0},
+		{"10.0.0.0/24", 33, 0},
+		{"0.0.0.0/0", 32, 4294967296},
+		{"0.0.0.0/0", 1, 2},
+		{"128.0.0.0/1", 32, 2147483648},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		count := net.SubnetCount64(c.prefix)
+		if count != c.expect {
+			t.Errorf("%s.SubnetCount64(%d) Expect: %d  Result: %d", c.net, c.prefix, c.expect, count)
+		}
+	}
+}
+
 func Test_IPv4Net_Summ(t *testing.T) {
 	cases := []struct {
 		net    string
@@ -552,3 +2004,172 @@ func Test_IPv4Net_Summ(t *testing.T) {
 		}
 	}
 }
+
+func Test_IPv4Net_Supernet(t *testing.T) {
+	cases := []struct {
+		net    string
+		prefix uint
+		expect string
+		err    bool
+	}{
+		{"10.1.2.128/25", 24, "10.1.2.0/24", false},
+		{"10.1.2.128/25", 16, "10.1.0.0/16", false},
+		{"10.1.2.128/25", 25, "10.1.2.128/25", false},
+		{"10.1.2.128/25", 0, "0.0.0.0/0", false},
+		{"10.1.2.128/25", 26, "", true},
+		{"10.1.2.128/25", 33, "", true},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		supernet, err := net.Supernet(c.prefix)
+		if err != nil {
+			if !c.err {
+				t.Errorf("%s.Supernet(%d) unexpected error: %s", c.net, c.prefix, err.Error())
+			}
+			continue
+		} else if c.err {
+			t.Errorf("%s.Supernet(%d) expected error but none raised", c.net, c.prefix)
+			continue
+		}
+		if supernet.String() != c.expect {
+			t.Errorf("%s.Supernet(%d) Expect: %s  Result: %s", c.net, c.prefix, c.expect, supernet)
+		}
+	}
+}
+
+func Test_IPv4Net_TryMerge(t *testing.T) {
+	cases := []struct {
+		net    string
+		other  string
+		expect string
+		ok     bool
+	}{
+		{"10.0.0.0/25", "10.0.0.128/25", "10.0.0.0/24", true}, // summarizable
+		{"10.0.1.0/24", "10.0.2.0/24", "", false},             // adjacent but not aligned
+		{"10.0.0.0/24", "10.0.1.0/25", "", false},             // different sizes
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		other, _ := ParseIPv4Net(c.other)
+		merged, ok := net.TryMerge(other)
+		if ok != c.ok {
+			t.Errorf("%s.TryMerge(%s) Expect: %t  Result: %t", c.net, c.other, c.ok, ok)
+		} else if ok && merged.String() != c.expect {
+			t.Errorf("%s.TryMerge(%s) Expect: %s  Result: %s", c.net, c.other, c.expect, merged)
+		}
+	}
+}
+
+func BenchmarkIPv4Net_EqualCIDRString(b *testing.B) {
+	net, _ := ParseIPv4Net("192.168.1.0/24")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		net.EqualCIDRString("192.168.1.0/24")
+	}
+}
+
+func BenchmarkIPv4Net_ParseCmp(b *testing.B) {
+	net, _ := ParseIPv4Net("192.168.1.0/24")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		other, _ := ParseIPv4Net("192.168.1.0/24")
+		net.Cmp(other)
+	}
+}
+
+func Test_IPv4Net_UsableCount(t *testing.T) {
+	cases := []struct {
+		net    string
+		usable uint32
+		len    uint32
+	}{
+		{"0.0.0.0/0", 4294967294, 0},
+		{"0.0.0.0/1", 2147483646, 2147483648},
+		{"10.0.0.0/24", 254, 256},
+		{"10.0.0.0/30", 2, 4},
+		{"10.0.0.0/31", 2, 2},
+		{"10.0.0.0/32", 1, 1},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		if net.UsableCount() != c.usable {
+			t.Errorf("%s.UsableCount() Expect: %d  Result: %d", c.net, c.usable, net.UsableCount())
+		}
+		if net.Len() != c.len {
+			t.Errorf("%s.Len() Expect: %d  Result: %d", c.net, c.len, net.Len())
+		}
+	}
+}
+
+func Test_IPv4Net_WalkSubnets(t *testing.T) {
+	cases := []struct {
+		net    string
+		prefix uint
+		stop   int // stop once this many subnets are visited
+		expect []string
+	}{
+		{"10.0.0.0/24", 26, -1, []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}},
+		{"10.0.0.0/24", 28, 2, []string{"10.0.0.0/28", "10.0.0.16/28"}},
+		{"255.255.255.0/24", 25, -1, []string{"255.255.255.0/25", "255.255.255.128/25"}},
+		{"0.0.0.0/0", 1, -1, []string{"0.0.0.0/1", "128.0.0.0/1"}},
+		{"0.0.0.0/0", 32, 1, []string{"0.0.0.0/32"}},
+		{"10.0.0.0/24", 24, -1, []string{}},
+		{"10.0.0.0/24", 33, -1, []string{}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv4Net(c.net)
+		visited := IPv4NetList{}
+		net.WalkSubnets(c.prefix, func(sub *IPv4Net) bool {
+			visited = append(visited, sub)
+			return len(visited) != c.stop
+		})
+		if fmt.Sprint(visited) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.WalkSubnets(%d) Expect: %v  Result: %v", c.net, c.prefix, c.expect, visited)
+		}
+	}
+}
+
+func Test_IPv4Net_Std(t *testing.T) {
+	net, _ := ParseIPv4Net("10.0.0.0/8")
+	_, expect, _ := stdnet.ParseCIDR("10.0.0.0/8")
+	if std := net.ToStdIPNet(); std.String() != expect.String() || !std.IP.Equal(expect.IP) {
+		t.Errorf("%s.ToStdIPNet() Expect: %s  Result: %s", net, expect, std)
+	}
+	if prefix := net.ToNetipPrefix(); prefix != netip.MustParsePrefix("10.0.0.0/8") {
+		t.Errorf("%s.ToNetipPrefix() Expect: 10.0.0.0/8  Result: %s", net, prefix)
+	}
+
+	converted, err := IPv4NetFromStdIPNet(expect)
+	if err != nil || converted.String() != "10.0.0.0/8" {
+		t.Errorf("IPv4NetFromStdIPNet(%s) Expect: 10.0.0.0/8  Result: %s %v", expect, converted, err)
+	}
+	_, other, _ := stdnet.ParseCIDR("2001:db8::/32")
+	for _, n := range []*stdnet.IPNet{nil, other, {IP: expect.IP, Mask: stdnet.IPMask{0xff, 0, 0xff, 0}}} {
+		if _, err := IPv4NetFromStdIPNet(n); err == nil {
+			t.Errorf("IPv4NetFromStdIPNet(%v) Expect: error  Result: nil", n)
+		}
+	}
+}
+
+func Test_IPv4Net_SQL(t *testing.T) {
+	net, _ := ParseIPv4Net("10.0.0.0/8")
+	if v, err := net.Value(); err != nil || v != "10.0.0.0/8" {
+		t.Errorf("%s.Value() Expect: 10.0.0.0/8  Result: %v %v", net, v, err)
+	}
+
+	for _, src := range []interface{}{"192.168.1.0/24", []byte("192.168.1.0/24")} {
+		scanned := new(IPv4Net)
+		if err := scanned.Scan(src); err != nil || scanned.String() != "192.168.1.0/24" {
+			t.Errorf("Scan(%v) Expect: 192.168.1.0/24  Result: %s %v", src, scanned, err)
+		}
+	}
+	for _, src := range []interface{}{"10.0.0.0/33", 1, nil} {
+		if err := new(IPv4Net).Scan(src); err == nil {
+			t.Errorf("Scan(%v) Expect: error  Result: nil", src)
+		}
+	}
+}
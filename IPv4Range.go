@@ -0,0 +1,55 @@
+package netaddr
+
+import (
+	"fmt"
+)
+
+// IPv4Range represents an inclusive, contiguous span of IPv4 addresses which,
+// unlike an IPv4Net, need not be aligned on a bit boundary.
+type IPv4Range struct {
+	first *IPv4
+	last  *IPv4
+}
+
+// NewIPv4Range creates an IPv4Range spanning first through last inclusive.
+// An error is returned if either argument is nil or if first is greater than last.
+func NewIPv4Range(first, last *IPv4) (*IPv4Range, error) {
+	if first == nil || last == nil {
+		return nil, fmt.Errorf("Arguments first and last must not be nil.")
+	}
+	if first.addr > last.addr {
+		return nil, fmt.Errorf("First address %s is greater than last address %s.", first, last)
+	}
+	return &IPv4Range{first: first, last: last}, nil
+}
+
+// Contains returns true if ip falls within the range.
+func (r *IPv4Range) Contains(ip *IPv4) bool {
+	return ip != nil && ip.addr >= r.first.addr && ip.addr <= r.last.addr
+}
+
+// First returns the first address of the range.
+func (r *IPv4Range) First() *IPv4 {
+	return r.first
+}
+
+// Last returns the last address of the range.
+func (r *IPv4Range) Last() *IPv4 {
+	return r.last
+}
+
+// Len returns the number of addresses within the range.
+// It is a uint64 since the range 0.0.0.0-255.255.255.255 holds 2^32 addresses.
+func (r *IPv4Range) Len() uint64 {
+	return uint64(r.last.addr) - uint64(r.first.addr) + 1
+}
+
+// Nets returns the minimal list of networks which exactly cover the range.
+func (r *IPv4Range) Nets() IPv4NetList {
+	return ipv4RangeNets(uint64(r.first.addr), uint64(r.last.addr)+1)
+}
+
+// String returns the range as "first-last", eg. "192.168.1.10-192.168.1.20".
+func (r *IPv4Range) String() string {
+	return r.first.String() + "-" + r.last.String()
+}
@@ -0,0 +1,80 @@
+package netaddr
+
+import "testing"
+import "fmt"
+
+func Test_NewIPv4Range(t *testing.T) {
+	cases := []struct {
+		first string
+		last  string
+		len   uint64
+		err   bool
+	}{
+		{"10.0.0.1", "10.0.0.1", 1, false},
+		{"10.0.0.10", "10.0.1.9", 256, false},
+		{"0.0.0.0", "255.255.255.255", 1 << 32, false},
+		{"10.0.0.2", "10.0.0.1", 0, true},
+	}
+
+	for _, c := range cases {
+		r, err := NewIPv4Range(MustParseIPv4(c.first), MustParseIPv4(c.last))
+		if c.err {
+			if err == nil {
+				t.Errorf("NewIPv4Range(%s, %s) Expect: error  Result: %s", c.first, c.last, r)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewIPv4Range(%s, %s) unexpected error: %s", c.first, c.last, err)
+		} else if r.Len() != c.len || r.String() != c.first+"-"+c.last {
+			t.Errorf("NewIPv4Range(%s, %s) Expect: len %d  Result: %s len %d", c.first, c.last, c.len, r, r.Len())
+		}
+	}
+
+	if _, err := NewIPv4Range(nil, MustParseIPv4("10.0.0.1")); err == nil {
+		t.Errorf("NewIPv4Range(nil, 10.0.0.1) Expect: error")
+	}
+}
+
+func Test_IPv4Range_Contains(t *testing.T) {
+	r, _ := NewIPv4Range(MustParseIPv4("10.0.0.10"), MustParseIPv4("10.0.0.20"))
+	cases := []struct {
+		ip     string
+		expect bool
+	}{
+		{"10.0.0.9", false},
+		{"10.0.0.10", true},
+		{"10.0.0.15", true},
+		{"10.0.0.20", true},
+		{"10.0.0.21", false},
+	}
+
+	for _, c := range cases {
+		if r.Contains(MustParseIPv4(c.ip)) != c.expect {
+			t.Errorf("%s.Contains(%s) Expect: %v  Result: %v", r, c.ip, c.expect, !c.expect)
+		}
+	}
+	if r.Contains(nil) {
+		t.Errorf("%s.Contains(nil) Expect: false  Result: true", r)
+	}
+}
+
+func Test_IPv4Range_Nets(t *testing.T) {
+	cases := []struct {
+		first  string
+		last   string
+		expect string
+	}{
+		{"10.0.0.0", "10.0.0.255", "[10.0.0.0/24]"},
+		{"10.0.0.10", "10.0.0.20", "[10.0.0.10/31 10.0.0.12/30 10.0.0.16/30 10.0.0.20/32]"},
+		{"0.0.0.0", "255.255.255.255", "[0.0.0.0/0]"},
+		{"255.255.255.255", "255.255.255.255", "[255.255.255.255/32]"},
+	}
+
+	for _, c := range cases {
+		r, _ := NewIPv4Range(MustParseIPv4(c.first), MustParseIPv4(c.last))
+		if nets := r.Nets(); fmt.Sprint(nets) != c.expect {
+			t.Errorf("%s.Nets() Expect: %s  Result: %v", r, c.expect, nets)
+		}
+	}
+}
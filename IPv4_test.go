@@ -2,6 +2,10 @@ package netaddr
 
 import "testing"
 import "fmt"
+import "strings"
+import stdnet "net"
+import "net/netip"
+import "sort"
 
 func ExampleParseIPv4() {
 	ip, _ := ParseIPv4("128.0.0.1")
@@ -15,6 +19,14 @@ func ExampleNewIPv4() {
 	// Output: 128.0.0.1
 }
 
+func ExampleIPv4_Addr() {
+	// the integer form round-trips through Addr() and NewIPv4()
+	ip, _ := ParseIPv4("128.0.0.1")
+	u32 := ip.Addr()
+	fmt.Println(u32, NewIPv4(u32+1))
+	// Output: 2147483649 128.0.0.2
+}
+
 func Test_ParseIPv4(t *testing.T) {
 	cases := []struct {
 		given string
@@ -51,6 +63,230 @@ func Test_ParseIPv4(t *testing.T) {
 	}
 }
 
+func Test_ParseIPv4Loose(t *testing.T) {
+	cases := []struct {
+		given string
+		addr  uint32
+		err   bool
+	}{
+		{" 192.168.1.1 ", 0xc0a80101, false},
+		{"0xC0.0xA8.0x01.0x01", 0xc0a80101, false},
+		{"0300.0250.01.01", 0xc0a80101, false},
+		{"0300.0xa8.1.01", 0xc0a80101, false},
+		{"3232235777", 0xc0a80101, false},
+		{"0xc0a80101", 0xc0a80101, false},
+		{"030052000401", 0xc0a80101, false},
+		{"192.168.257", 0xc0a80101, false},
+		{"192.11010305", 0xc0a80101, false},
+		{"0", 0, false},
+		{"4294967295", 0xffffffff, false},
+		{"4294967296", 0, true},
+		{"192.168.65536", 0, true},
+		{"256.0.0.1", 0, true},
+		{"09.0.0.1", 0, true},
+		{"0x.0.0.1", 0, true},
+		{"1.2.3.4.5", 0, true},
+		{"1..2.3", 0, true},
+		{"+1.2.3.4", 0, true},
+		{"1_0.0.0.1", 0, true},
+		{"", 0, true},
+	}
+
+	for _, c := range cases {
+		ip, err := ParseIPv4Loose(c.given)
+		if err != nil {
+			if !c.err {
+				t.Errorf("ParseIPv4Loose(%s) unexpected parse error: %s", c.given, err.Error())
+			}
+			continue
+		}
+
+		if c.err {
+			t.Errorf("ParseIPv4Loose(%s) expected error but none raised", c.given)
+			continue
+		}
+
+		if ip.addr != c.addr {
+			t.Errorf("ParseIPv4Loose(%s).addr  Expect: %x  Result: %x", c.given, c.addr, ip.addr)
+		}
+	}
+}
+
+// FuzzParseIPv4 checks that any address accepted by ParseIPv4 round-trips through String.
+func FuzzParseIPv4(f *testing.F) {
+	for _, seed := range []string{"0.0.0.0", "192.168.1.1", " 10.0.0.1 ", "255.255.255.255", "01.2.3.4", "1.2.3", "a.b.c.d"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ip, err := ParseIPv4(s)
+		if err != nil {
+			return
+		}
+		reparsed, err := ParseIPv4(ip.String())
+		if err != nil {
+			t.Fatalf("ParseIPv4(%q) = %s which failed to reparse: %s", s, ip, err)
+		}
+		if reparsed.addr != ip.addr || reparsed.String() != ip.String() {
+			t.Fatalf("ParseIPv4(%q) = %s which reparsed as %s", s, ip, reparsed)
+		}
+	})
+}
+
+func Test_MustParseIPv4(t *testing.T) {
+	if parsed := MustParseIPv4("10.0.0.1"); parsed.String() != "10.0.0.1" {
+		t.Errorf("MustParseIPv4(10.0.0.1) Expect: 10.0.0.1  Result: %s", parsed)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "10.0.0.256") {
+			t.Errorf("MustParseIPv4(10.0.0.256) expected a panic naming the input. Result: %v", r)
+		}
+	}()
+	MustParseIPv4("10.0.0.256")
+}
+
+func Test_ParseIPv4OrDefault(t *testing.T) {
+	def := MustParseIPv4("192.168.0.1")
+	if parsed := ParseIPv4OrDefault("10.0.0.1", def); parsed.String() != "10.0.0.1" {
+		t.Errorf("ParseIPv4OrDefault(10.0.0.1) Expect: 10.0.0.1  Result: %s", parsed)
+	}
+	if parsed := ParseIPv4OrDefault("10.0.0.256", def); parsed != def {
+		t.Errorf("ParseIPv4OrDefault(10.0.0.256) Expect: %s  Result: %s", def, parsed)
+	}
+	if parsed := ParseIPv4OrDefault("", nil); parsed != nil {
+		t.Errorf("ParseIPv4OrDefault() Expect: nil  Result: %s", parsed)
+	}
+}
+
+func Test_IPv4FromBytes(t *testing.T) {
+	cases := []struct {
+		given     []byte
+		expect    string
+		expectErr bool
+	}{
+		{[]byte{192, 168, 1, 1}, "192.168.1.1", false},
+		{[]byte{0, 0, 0, 0}, "0.0.0.0", false},
+		{[]byte{255, 255, 255, 255}, "255.255.255.255", false},
+		{[]byte{192, 168, 1}, "", true},
+		{[]byte{192, 168, 1, 1, 1}, "", true},
+		{nil, "", true},
+	}
+
+	for _, c := range cases {
+		ip, err := IPv4FromBytes(c.given)
+		if err != nil {
+			if !c.expectErr {
+				t.Errorf("IPv4FromBytes(%v) unexpected error: %s", c.given, err)
+			} else if !strings.Contains(err.Error(), fmt.Sprintf("Received %d bytes", len(c.given))) {
+				t.Errorf("IPv4FromBytes(%v) error does not state the received length: %s", c.given, err)
+			}
+			continue
+		}
+		if c.expectErr {
+			t.Errorf("IPv4FromBytes(%v) expected error but none raised", c.given)
+			continue
+		}
+		if ip.String() != c.expect {
+			t.Errorf("IPv4FromBytes(%v) Expect: %s  Result: %s", c.given, c.expect, ip)
+		}
+		if fmt.Sprint(ip.Bytes()) != fmt.Sprint(c.given) {
+			t.Errorf("%s.Bytes() Expect: %v  Result: %v", ip, c.given, ip.Bytes())
+		}
+	}
+}
+
+func Test_IPv4_Add(t *testing.T) {
+	cases := []struct {
+		ip     string
+		n      uint32
+		expect string
+	}{
+		{"192.168.1.0", 0, "192.168.1.0"},
+		{"192.168.1.0", 256, "192.168.2.0"},
+		{"255.255.255.0", 255, "255.255.255.255"},
+		{"255.255.255.0", 256, ""},
+		{"0.0.0.1", F32, ""},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.ip)
+		res := ip.Add(c.n)
+		if res == nil {
+			if c.expect != "" {
+				t.Errorf("%s.Add(%d) Expect: %s  Result: nil", c.ip, c.n, c.expect)
+			}
+		} else if res.String() != c.expect {
+			t.Errorf("%s.Add(%d) Expect: %s  Result: %s", c.ip, c.n, c.expect, res)
+		}
+	}
+}
+
+func Test_IPv4_Bit(t *testing.T) {
+	ip, _ := ParseIPv4("128.0.0.5") // 10000000 ... 00000101
+	cases := []struct {
+		pos    uint
+		expect int
+	}{
+		{0, 1},
+		{1, 0},
+		{29, 1},
+		{30, 0},
+		{31, 1},
+		{32, 0},
+	}
+
+	for _, c := range cases {
+		if bit := ip.Bit(c.pos); bit != c.expect {
+			t.Errorf("%s.Bit(%d) Expect: %d  Result: %d", ip, c.pos, c.expect, bit)
+		}
+	}
+}
+
+func Test_IPv4_CommonPrefixLen(t *testing.T) {
+	cases := []struct {
+		ip     string
+		other  string
+		expect uint
+	}{
+		{"10.0.0.1", "10.0.0.1", 32},
+		{"10.0.0.0", "10.0.0.1", 31},
+		{"10.0.0.0", "10.0.0.255", 24},
+		{"10.0.0.0", "10.128.0.0", 8},
+		{"0.0.0.0", "128.0.0.0", 0},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.ip)
+		other, _ := ParseIPv4(c.other)
+		if l := ip.CommonPrefixLen(other); l != c.expect {
+			t.Errorf("%s.CommonPrefixLen(%s) Expect: %d  Result: %d", c.ip, c.other, c.expect, l)
+		}
+		for pos := uint(0); pos < c.expect; pos++ {
+			if ip.Bit(pos) != other.Bit(pos) {
+				t.Errorf("%s.Bit(%d) differs from %s within the common prefix", c.ip, pos, c.other)
+			}
+		}
+	}
+
+	ip, _ := ParseIPv4("10.0.0.1")
+	if l := ip.CommonPrefixLen(nil); l != 0 {
+		t.Errorf("%s.CommonPrefixLen(nil) Expect: 0  Result: %d", ip, l)
+	}
+}
+
+func Test_IPv4_Clone(t *testing.T) {
+	ip, _ := ParseIPv4("192.168.1.1")
+	clone := ip.Clone()
+	if clone == ip || clone.addr != ip.addr {
+		t.Errorf("%s.Clone() Expect: an independent copy  Result: %s", ip, clone)
+	}
+	clone.addr = 0
+	if ip.String() != "192.168.1.1" {
+		t.Errorf("%s.Clone() modification of the copy changed the original", ip)
+	}
+}
+
 func Test_IPv4_Cmp(t *testing.T) {
 	cases := []struct {
 		ip1 string
@@ -72,6 +308,270 @@ func Test_IPv4_Cmp(t *testing.T) {
 	}
 }
 
+func Test_IPv4_Distance(t *testing.T) {
+	cases := []struct {
+		ip1    string
+		ip2    string
+		expect int64
+	}{
+		{"10.0.0.0", "10.0.1.0", 256},
+		{"10.0.1.0", "10.0.0.0", -256},
+		{"10.0.0.0", "10.0.0.0", 0},
+		{"0.0.0.0", "255.255.255.255", 0xffffffff},
+		{"255.255.255.255", "0.0.0.0", -0xffffffff},
+	}
+
+	for _, c := range cases {
+		ip1, _ := ParseIPv4(c.ip1)
+		ip2, _ := ParseIPv4(c.ip2)
+		if res, _ := ip1.Distance(ip2); res != c.expect {
+			t.Errorf("%s.Distance(%s) Expect: %d  Result: %d", c.ip1, c.ip2, c.expect, res)
+		}
+	}
+
+	ip, _ := ParseIPv4("10.0.0.0")
+	if _, err := ip.Distance(nil); err == nil {
+		t.Errorf("%s.Distance(nil) expected error but none raised", ip)
+	}
+}
+
+func Test_IPv4_AltStrings(t *testing.T) {
+	cases := []struct {
+		given  string
+		hex    string
+		octal  string
+		uint32 string
+	}{
+		{"192.168.1.1", "0xc0.0xa8.0x01.0x01", "0300.0250.01.01", "3232235777"},
+		{"0.0.0.0", "0x00.0x00.0x00.0x00", "00.00.00.00", "0"},
+		{"255.255.255.255", "0xff.0xff.0xff.0xff", "0377.0377.0377.0377", "4294967295"},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.given)
+		if str := ip.DottedHex(); str != c.hex {
+			t.Errorf("%s.DottedHex() Expect: %s  Result: %s", c.given, c.hex, str)
+		}
+		if str := ip.OctalEscaped(); str != c.octal {
+			t.Errorf("%s.OctalEscaped() Expect: %s  Result: %s", c.given, c.octal, str)
+		}
+		if str := ip.Uint32String(); str != c.uint32 {
+			t.Errorf("%s.Uint32String() Expect: %s  Result: %s", c.given, c.uint32, str)
+		}
+		for _, str := range []string{c.hex, c.octal, c.uint32} {
+			if parsed, err := ParseIPv4Loose(str); err != nil || parsed.addr != ip.addr {
+				t.Errorf("ParseIPv4Loose(%s) Expect: %s  Result: %v, %v", str, c.given, parsed, err)
+			}
+		}
+	}
+}
+
+func Test_IPv4_Format(t *testing.T) {
+	cases := []struct {
+		format string
+		expect string
+	}{
+		{"%s", "192.168.1.1"},
+		{"%v", "192.168.1.1"},
+		{"%x", "c0a80101"},
+		{"%X", "C0A80101"},
+		{"%b", "11000000101010000000000100000001"},
+		{"%-15s|", "192.168.1.1    |"},
+		{"%15v|", "    192.168.1.1|"},
+		{"%q", `"192.168.1.1"`},
+		{"%#v", "IPv4{addr:0xc0a80101 (192.168.1.1)}"},
+	}
+
+	ip, _ := ParseIPv4("192.168.1.1")
+	for _, c := range cases {
+		if res := fmt.Sprintf(c.format, ip); res != c.expect {
+			t.Errorf("Sprintf(%s, %s) Expect: %s  Result: %s", c.format, ip, c.expect, res)
+		}
+	}
+
+	// leading zeros are kept for hex and binary
+	ip, _ = ParseIPv4("0.0.0.1")
+	if res := fmt.Sprintf("%x %b", ip, ip); res != "00000001 00000000000000000000000000000001" {
+		t.Errorf("Sprintf(%%x %%b, %s) Result: %s", ip, res)
+	}
+}
+
+func Test_IPv4_Classifiers(t *testing.T) {
+	cases := []struct {
+		given         string
+		linkLocal     bool
+		multicast     bool
+		loopback      bool
+		unspecified   bool
+		globalUnicast bool
+	}{
+		{"169.254.1.1", true, false, false, false, false},
+		{"169.255.0.0", false, false, false, false, true},
+		{"224.0.0.1", false, true, false, false, false},
+		{"239.255.255.255", false, true, false, false, false},
+		{"240.0.0.1", false, false, false, false, true},
+		{"127.0.0.1", false, false, true, false, false},
+		{"127.255.255.255", false, false, true, false, false},
+		{"0.0.0.0", false, false, false, true, false},
+		{"255.255.255.255", false, false, false, false, false},
+		{"10.0.0.1", false, false, false, false, true},
+		{"8.8.8.8", false, false, false, false, true},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.given)
+		if ip.IsLinkLocal() != c.linkLocal {
+			t.Errorf("%s.IsLinkLocal() Expect: %t  Result: %t", c.given, c.linkLocal, !c.linkLocal)
+		}
+		if ip.IsMulticast() != c.multicast {
+			t.Errorf("%s.IsMulticast() Expect: %t  Result: %t", c.given, c.multicast, !c.multicast)
+		}
+		if ip.IsLoopback() != c.loopback {
+			t.Errorf("%s.IsLoopback() Expect: %t  Result: %t", c.given, c.loopback, !c.loopback)
+		}
+		if ip.IsUnspecified() != c.unspecified {
+			t.Errorf("%s.IsUnspecified() Expect: %t  Result: %t", c.given, c.unspecified, !c.unspecified)
+		}
+		if ip.IsGlobalUnicast() != c.globalUnicast {
+			t.Errorf("%s.IsGlobalUnicast() Expect: %t  Result: %t", c.given, c.globalUnicast, !c.globalUnicast)
+		}
+	}
+}
+
+func Test_IPv4_IsPrivate_IsDocumentation(t *testing.T) {
+	cases := []struct {
+		given         string
+		private       bool
+		documentation bool
+	}{
+		{"10.0.0.1", true, false},
+		{"172.16.0.1", true, false},
+		{"172.31.255.255", true, false},
+		{"172.32.0.1", false, false},
+		{"192.168.1.1", true, false},
+		{"192.169.0.1", false, false},
+		{"192.0.2.1", false, true},
+		{"198.51.100.255", false, true},
+		{"203.0.113.0", false, true},
+		{"203.0.114.0", false, false},
+		{"8.8.8.8", false, false},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.given)
+		if ip.IsPrivate() != c.private {
+			t.Errorf("%s.IsPrivate() Expect: %t  Result: %t", c.given, c.private, !c.private)
+		}
+		if ip.IsDocumentation() != c.documentation {
+			t.Errorf("%s.IsDocumentation() Expect: %t  Result: %t", c.given, c.documentation, !c.documentation)
+		}
+	}
+}
+
+func Test_IPv4_Less(t *testing.T) {
+	cases := []struct {
+		ip1 *IPv4
+		ip2 *IPv4
+		res bool
+	}{
+		{MustParseIPv4("1.1.1.0"), MustParseIPv4("1.1.2.0"), true},
+		{MustParseIPv4("1.1.1.0"), MustParseIPv4("1.1.0.0"), false},
+		{MustParseIPv4("1.1.1.0"), MustParseIPv4("1.1.1.0"), false},
+		{MustParseIPv4("255.255.255.255"), nil, true}, // nil is greatest
+		{nil, MustParseIPv4("0.0.0.0"), false},
+		{nil, nil, false},
+	}
+
+	for _, c := range cases {
+		if res := c.ip1.Less(c.ip2); res != c.res {
+			t.Errorf("%v.Less(%v) Expect: %v  Result: %v", c.ip1, c.ip2, c.res, res)
+		}
+	}
+
+	ips := []*IPv4{MustParseIPv4("10.0.0.2"), nil, MustParseIPv4("10.0.0.0"), MustParseIPv4("10.0.0.1")}
+	sort.Slice(ips, func(i, j int) bool { return ips[i].Less(ips[j]) })
+	if ips[0].String() != "10.0.0.0" || ips[1].String() != "10.0.0.1" || ips[2].String() != "10.0.0.2" || ips[3] != nil {
+		t.Errorf("sort.Slice using Less Expect: [10.0.0.0 10.0.0.1 10.0.0.2 <nil>]  Result: %v", ips)
+	}
+}
+
+func Test_IPv4_MulticastScope(t *testing.T) {
+	cases := []struct {
+		given string
+		scope string
+	}{
+		{"224.0.0.0", "local-control"},
+		{"224.0.0.251", "local-control"},
+		{"224.0.1.1", "internetwork-control"},
+		{"224.0.2.0", "other"},
+		{"231.255.255.255", "other"},
+		{"232.0.0.1", "source-specific"},
+		{"232.255.255.255", "source-specific"},
+		{"233.0.0.0", "glop"},
+		{"233.251.255.255", "glop"},
+		{"233.252.0.0", "other"}, // MCAST-TEST-NET
+		{"234.0.0.1", "other"},
+		{"239.0.0.0", "admin-scoped"},
+		{"239.255.255.255", "admin-scoped"},
+		{"223.255.255.255", ""},
+		{"240.0.0.0", ""},
+		{"10.0.0.1", ""},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.given)
+		if scope := ip.MulticastScope(); scope != c.scope {
+			t.Errorf("%s.MulticastScope() Expect: %q  Result: %q", c.given, c.scope, scope)
+		}
+		checks := map[string]bool{
+			"local-control":        ip.IsLocalControlMulticast(),
+			"internetwork-control": ip.IsInternetworkControlMulticast(),
+			"source-specific":      ip.IsSourceSpecificMulticast(),
+			"glop":                 ip.IsGLOPMulticast(),
+			"admin-scoped":         ip.IsAdminScopedMulticast(),
+		}
+		for scope, res := range checks {
+			if res != (scope == c.scope) {
+				t.Errorf("%s %s predicate Expect: %v  Result: %v", c.given, scope, !res, res)
+			}
+		}
+	}
+}
+
+func Test_IPv4_Mask(t *testing.T) {
+	cases := []struct {
+		ip        string
+		prefixLen uint
+		expect    string
+	}{
+		{"192.168.1.130", 24, "192.168.1.0"},
+		{"192.168.1.130", 25, "192.168.1.128"},
+		{"192.168.1.130", 32, "192.168.1.130"},
+		{"192.168.1.130", 0, "0.0.0.0"},
+		{"255.255.255.255", 1, "128.0.0.0"},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.ip)
+		masked, err := ip.Mask(c.prefixLen)
+		if err != nil {
+			t.Errorf("%s.Mask(%d) unexpected error: %s", c.ip, c.prefixLen, err)
+			continue
+		}
+		if masked.String() != c.expect {
+			t.Errorf("%s.Mask(%d) Expect: %s  Result: %s", c.ip, c.prefixLen, c.expect, masked)
+		}
+		if ip.String() != c.ip {
+			t.Errorf("%s.Mask(%d) modified the original address: %s", c.ip, c.prefixLen, ip)
+		}
+	}
+
+	ip, _ := ParseIPv4("192.168.1.130")
+	if _, err := ip.Mask(33); err == nil {
+		t.Errorf("%s.Mask(33) Expect: error  Result: nil", ip)
+	}
+}
+
 func Test_MulticastMac(t *testing.T) {
 	cases := []struct {
 		ip  string
@@ -95,7 +595,7 @@ func Test_MulticastMac(t *testing.T) {
 }
 
 func Test_IPv4_String(t *testing.T) {
-	cases := []string{"0.0.0.0", "192.168.1.0", "1.2.3.4"}
+	cases := []string{"0.0.0.0", "192.168.1.0", "1.2.3.4", "255.255.255.255", "10.100.9.99"}
 
 	for _, c := range cases {
 		ip, _ := ParseIPv4(c)
@@ -103,6 +603,121 @@ func Test_IPv4_String(t *testing.T) {
 			t.Errorf("%s.String() Expect: %s  Result: %s", c, c, ip.String())
 		}
 	}
+
+	// output must match the previous fmt based formatting
+	for addr := uint64(0); addr <= 0xffffffff; addr += 0x01010101 / 7 {
+		ip := NewIPv4(uint32(addr))
+		expect := fmt.Sprintf("%d.%d.%d.%d", ip.addr>>24&0xff, ip.addr>>16&0xff, ip.addr>>8&0xff, ip.addr&0xff)
+		if ip.String() != expect {
+			t.Errorf("%#x String() Expect: %s  Result: %s", ip.addr, expect, ip.String())
+		}
+	}
+}
+
+func Test_IPv4_AppendTo(t *testing.T) {
+	ip := NewIPv4(0xc0a80101)
+	buf := []byte("ip=")
+	if res := string(ip.AppendTo(buf)); res != "ip=192.168.1.1" {
+		t.Errorf("%s.AppendTo(ip=) Expect: ip=192.168.1.1  Result: %s", ip, res)
+	}
+
+	buf = make([]byte, 0, 15)
+	allocs := testing.AllocsPerRun(100, func() { buf = ip.AppendTo(buf[:0]) })
+	if allocs != 0 {
+		t.Errorf("%s.AppendTo() Expect: 0 allocations  Result: %v", ip, allocs)
+	}
+}
+
+func Test_IPv4_PTR(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"192.168.1.1", "1.1.168.192.in-addr.arpa."},
+		{"0.0.0.0", "0.0.0.0.in-addr.arpa."},
+		{"255.255.255.255", "255.255.255.255.in-addr.arpa."},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.given)
+		if ip.PTR() != c.expect {
+			t.Errorf("%s.PTR() Expect: %s  Result: %s", c.given, c.expect, ip.PTR())
+		}
+		if b := ip.AppendPTR([]byte("x ")); string(b) != "x "+c.expect {
+			t.Errorf("%s.AppendPTR() Expect: x %s  Result: %s", c.given, c.expect, b)
+		}
+	}
+}
+
+func Test_IPv4_Sub(t *testing.T) {
+	cases := []struct {
+		ip     string
+		n      uint32
+		expect string
+	}{
+		{"192.168.1.0", 0, "192.168.1.0"},
+		{"192.168.2.0", 256, "192.168.1.0"},
+		{"0.0.0.255", 255, "0.0.0.0"},
+		{"0.0.0.255", 256, ""},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.ip)
+		res := ip.Sub(c.n)
+		if res == nil {
+			if c.expect != "" {
+				t.Errorf("%s.Sub(%d) Expect: %s  Result: nil", c.ip, c.n, c.expect)
+			}
+		} else if res.String() != c.expect {
+			t.Errorf("%s.Sub(%d) Expect: %s  Result: %s", c.ip, c.n, c.expect, res)
+		}
+	}
+}
+
+func Test_IPv4_To6to4(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"192.168.1.1", "2002:c0a8:101::"},
+		{"0.0.0.0", "2002::"},
+		{"255.255.255.255", "2002:ffff:ffff::"},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.given)
+		ip6 := ip.To6to4()
+		if ip6.String() != c.expect {
+			t.Errorf("%s.To6to4() Expect: %s  Result: %s", c.given, c.expect, ip6)
+		}
+		if ip6.To4().String() != c.given {
+			t.Errorf("%s.To4() Expect: %s  Result: %s", ip6, c.given, ip6.To4())
+		}
+	}
+}
+
+func Test_IPv4_ToMapped(t *testing.T) {
+	cases := []struct {
+		given  string
+		hostId uint64
+	}{
+		{"192.168.1.1", 0xffffc0a80101},
+		{"0.0.0.0", 0xffff00000000},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv4(c.given)
+		ip6 := ip.ToMapped()
+		if ip6.netId != 0 || ip6.hostId != c.hostId {
+			t.Errorf("%s.ToMapped() Expect: 0%x  Result: %x%x", c.given, c.hostId, ip6.netId, ip6.hostId)
+		}
+		if !ip6.Is4Mapped() {
+			t.Errorf("%s.Is4Mapped() Expect: true  Result: false", ip6)
+		}
+		if ip6.To4().String() != c.given {
+			t.Errorf("%s.To4() Expect: %s  Result: %s", ip6, c.given, ip6.To4())
+		}
+	}
 }
 
 func Test_Ipv4_ToNet(t *testing.T) {
@@ -113,3 +728,74 @@ func Test_Ipv4_ToNet(t *testing.T) {
 		t.Errorf("%s.ToNet() Expect: %s  Result: %s", ip, net, ip.ToNet())
 	}
 }
+
+func BenchmarkIPv4_AppendPTR(b *testing.B) {
+	ip := NewIPv4(0xc0a80101)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = ip.AppendPTR(buf[:0])
+	}
+}
+
+func BenchmarkIPv4_PTRSprintf(b *testing.B) {
+	ip := NewIPv4(0xc0a80101)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", ip.addr&0xff, ip.addr>>8&0xff, ip.addr>>16&0xff, ip.addr>>24&0xff)
+	}
+}
+
+func BenchmarkIPv4_String(b *testing.B) {
+	ip := NewIPv4(0xc0a80101)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = ip.String()
+	}
+}
+
+func BenchmarkIPv4_AppendTo(b *testing.B) {
+	ip := NewIPv4(0xc0a80101)
+	buf := make([]byte, 0, 15)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = ip.AppendTo(buf[:0])
+	}
+}
+
+func BenchmarkIPv4_StringSprintf(b *testing.B) {
+	ip := NewIPv4(0xc0a80101)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fmt.Sprintf("%d.%d.%d.%d", ip.addr>>24&0xff, ip.addr>>16&0xff, ip.addr>>8&0xff, ip.addr&0xff)
+	}
+}
+
+func Test_IPv4_Std(t *testing.T) {
+	ip, _ := ParseIPv4("192.168.1.1")
+	if std := ip.ToStdIP(); !std.Equal(stdnet.ParseIP("192.168.1.1")) || len(std) != 4 {
+		t.Errorf("%s.ToStdIP() Expect: 192.168.1.1  Result: %v", ip, std)
+	}
+	if addr := ip.ToNetipAddr(); addr != netip.MustParseAddr("192.168.1.1") {
+		t.Errorf("%s.ToNetipAddr() Expect: 192.168.1.1  Result: %v", ip, addr)
+	}
+}
+
+func Test_IPv4_SQL(t *testing.T) {
+	ip, _ := ParseIPv4("192.168.1.1")
+	if v, err := ip.Value(); err != nil || v != "192.168.1.1" {
+		t.Errorf("%s.Value() Expect: 192.168.1.1  Result: %v %v", ip, v, err)
+	}
+
+	for _, src := range []interface{}{"10.0.0.1", []byte("10.0.0.1")} {
+		scanned := new(IPv4)
+		if err := scanned.Scan(src); err != nil || scanned.String() != "10.0.0.1" {
+			t.Errorf("Scan(%v) Expect: 10.0.0.1  Result: %s %v", src, scanned, err)
+		}
+	}
+	for _, src := range []interface{}{"10.0.0.256", 1, nil} {
+		if err := new(IPv4).Scan(src); err == nil {
+			t.Errorf("Scan(%v) Expect: error  Result: nil", src)
+		}
+	}
+}
@@ -1,7 +1,13 @@
 package netaddr
 
 import (
+	"database/sql/driver"
+	"encoding/binary"
 	"fmt"
+	"math/bits"
+	stdnet "net"
+	"net/netip"
+	"strconv"
 	"strings"
 )
 
@@ -9,6 +15,7 @@ type IPv6 struct {
 	netId  uint64 // upper 64 bits
 	hostId uint64 // lower 64 bits
 	str    string // cached String()
+	zone   string // scope zone identifier (eg. eth0), if any
 }
 
 /*
@@ -16,12 +23,23 @@ ParseIPv6 arses a string into an IPv6 type.
 IP address should be in one of the following formats and should not contain a netmask.
 	* long format (eg. 0000:0000:0000:0000:0000:0000:0000:0001)
 	* zero-compressed short format (eg. ::1)
+	* either of the above with an embedded IPv4 address (eg. ::ffff:192.168.1.1)
+	* any of the above followed by a scope zone identifier (eg. fe80::1%eth0)
 */
 func ParseIPv6(ip string) (*IPv6, error) {
 	ip = strings.TrimSpace(ip)
 
+	var zone string
+	if i := strings.IndexByte(ip, '%'); i != -1 {
+		zone = ip[i+1:]
+		if zone == "" {
+			return nil, fmt.Errorf("Error parsing '%s'. Zone identifier must not be empty.", ip)
+		}
+		ip = ip[:i]
+	}
+
 	if ip == "::" {
-		return new(IPv6), nil
+		return &IPv6{zone: zone}, nil
 	} // special case. just return zero address
 
 	var groups []string             // holds the 8 groups of hex strings representing the ipv6 addr
@@ -37,7 +55,10 @@ func ParseIPv6(ip string) (*IPv6, error) {
 			halves[1] = "0"
 		} // handle cases such as fe80::
 		upHalf := strings.Split(halves[0], ":")
-		loHalf := strings.Split(halves[1], ":")
+		loHalf, err := expandIPv4Tail(strings.Split(halves[1], ":"))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing '%s'. %s", ip, err.Error())
+		}
 		numGroups := len(upHalf) + len(loHalf)
 		if numGroups > 8 {
 			return nil, fmt.Errorf("Error parsing '%s'. Shorthand formatted address is too long.", ip)
@@ -49,7 +70,11 @@ func ParseIPv6(ip string) (*IPv6, error) {
 		groups = append(groups, loHalf...)
 
 	} else {
-		groups = strings.Split(ip, ":")
+		var err error
+		groups, err = expandIPv4Tail(strings.Split(ip, ":"))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing '%s'. %s", ip, err.Error())
+		}
 		if len(groups) > 8 {
 			return nil, fmt.Errorf("Error parsing '%s'. Address is too long.", ip)
 		} else if len(groups) < 8 {
@@ -70,9 +95,31 @@ func ParseIPv6(ip string) (*IPv6, error) {
 		addr.hostId = u64
 	}
 
+	addr.zone = zone
 	return addr, nil
 }
 
+// MustParseIPv6 is like ParseIPv6 but panics if the string cannot be parsed.
+// It simplifies the safe initialization of global variables and test fixtures.
+func MustParseIPv6(ip string) *IPv6 {
+	parsed, err := ParseIPv6(ip)
+	if err != nil {
+		panic(fmt.Sprintf("MustParseIPv6(%q): %s", ip, err.Error()))
+	}
+	return parsed
+}
+
+// CanonicalizeIPv6 parses an IPv6 address in any accepted format (see ParseIPv6), such as
+// one containing uppercase hex or leading zeros, and returns it in the canonical rfc5952
+// format. Addresses which are numerically identical canonicalize to the same string.
+func CanonicalizeIPv6(ip string) (string, error) {
+	parsed, err := ParseIPv6(ip)
+	if err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
+
 /*
 NewIPv6 creates an IPv6 type from a pair of uint64. The pair represents
 the upper/lower 64-bits of the address respectively
@@ -81,11 +128,57 @@ func NewIPv6(netId, hostId uint64) *IPv6 {
 	return &IPv6{netId: netId, hostId: hostId}
 }
 
+// IPv6FromBytes creates an IPv6 type from a slice of exactly 16 bytes in big-endian (network) order.
+func IPv6FromBytes(b []byte) (*IPv6, error) {
+	if len(b) != 16 {
+		return nil, fmt.Errorf("IPv6 address must be exactly 16 bytes. Received %d bytes.", len(b))
+	}
+	return NewIPv6(binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])), nil
+}
+
+// ARPA returns the reverse DNS name of this IPv6 within the ip6.arpa. zone. The address is
+// expanded to 32 nibbles which are written in reverse order, eg. 2001:db8::1 becomes
+// 1.0.0.0. (...) .8.b.d.0.1.0.0.2.ip6.arpa. The zone identifier, if any, is ignored.
+func (ip *IPv6) ARPA() string {
+	const hex = "0123456789abcdef"
+	b := make([]byte, 0, 73)
+	for _, word := range [2]uint64{ip.hostId, ip.netId} {
+		for i := 0; i < 16; i++ {
+			b = append(b, hex[word&0xf], '.')
+			word >>= 4
+		}
+	}
+	return string(append(b, "ip6.arpa."...))
+}
+
+// Bit returns the value (0 or 1) of the bit at position pos, where position 0 is the
+// most significant bit. Positions beyond 127 return 0.
+func (ip *IPv6) Bit(pos uint) int {
+	switch {
+	case pos < 64:
+		return int(ip.netId>>(63-pos)) & 1
+	case pos < 128:
+		return int(ip.hostId>>(127-pos)) & 1
+	}
+	return 0
+}
+
+// Bytes returns a slice containing each byte of the IPv6 in big-endian (network) order.
+// The zone identifier, if any, is not included.
+func (ip *IPv6) Bytes() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[:8], ip.netId)
+	binary.BigEndian.PutUint64(b[8:], ip.hostId)
+	return b
+}
+
 /*
 Cmp compares equality with another IPv6. Return:
 	* 1 if this IPv6 is numerically greater
 	* 0 if the two are equal
 	* -1 if this IPv6 is numerically less
+
+Addresses which are numerically equal are then ordered by their zone identifiers.
 */
 func (ip *IPv6) Cmp(other *IPv6) (int, error) {
 	if other == nil {
@@ -104,7 +197,29 @@ func (ip *IPv6) Cmp(other *IPv6) (int, error) {
 	} else if ip.netId < other.netId {
 		return -1, nil
 	}
-	return 0, nil
+	return strings.Compare(ip.zone, other.zone), nil
+}
+
+// CommonPrefixLen returns the number of leading bits which this address shares with other.
+// Zones are ignored. Returns 0 if other is nil.
+func (ip *IPv6) CommonPrefixLen(other *IPv6) uint {
+	if other == nil {
+		return 0
+	}
+	if ip.netId != other.netId {
+		return uint(bits.LeadingZeros64(ip.netId ^ other.netId))
+	}
+	return 64 + uint(bits.LeadingZeros64(ip.hostId^other.hostId))
+}
+
+// GobDecode implements gob.GobDecoder using the format of UnmarshalBinary.
+func (ip *IPv6) GobDecode(data []byte) error {
+	return ip.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder using the format of MarshalBinary.
+func (ip *IPv6) GobEncode() ([]byte, error) {
+	return ip.MarshalBinary()
 }
 
 // HostId returns the interal uint64 for the host id portion of the address.
@@ -112,6 +227,61 @@ func (ip *IPv6) HostId() uint64 {
 	return ip.hostId
 }
 
+// Is4Mapped returns true if this address is an IPv4-mapped address (::ffff:0:0/96).
+func (ip *IPv6) Is4Mapped() bool {
+	return ip.netId == 0 && ip.hostId>>32 == 0xffff
+}
+
+// IsDocumentation returns true if this address is reserved for use in documentation
+// (rfc3849), ie. 2001:db8::/32.
+func (ip *IPv6) IsDocumentation() bool {
+	return ip.netId>>32 == 0x20010db8
+}
+
+// IsGlobalUnicast returns true if this is a global unicast address. Following the
+// behavior of the standard library, this is any address other than the unspecified,
+// loopback, link-local, and multicast addresses. Unique local addresses are included.
+//
+// The IPv6 classifiers mirror those of IPv4 so that dual-stack code may classify
+// addresses of either version in the same way. IsUniqueLocal has no IPv4 counterpart.
+func (ip *IPv6) IsGlobalUnicast() bool {
+	return !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsLinkLocal() && !ip.IsMulticast()
+}
+
+// IsLinkLocal returns true if this is a link-local unicast address (fe80::/10).
+func (ip *IPv6) IsLinkLocal() bool {
+	return ip.netId>>54 == 0xfe80>>6
+}
+
+// IsLoopback returns true if this is the loopback address (::1).
+func (ip *IPv6) IsLoopback() bool {
+	return ip.netId == 0 && ip.hostId == 1
+}
+
+// IsMulticast returns true if this is a multicast address (ff00::/8).
+func (ip *IPv6) IsMulticast() bool {
+	return ip.netId>>56 == 0xff
+}
+
+// IsPrivate returns true if this is a private address. For IPv6 these are the unique
+// local addresses (fc00::/7); it is equivalent to IsUniqueLocal and is provided for
+// parity with IPv4.IsPrivate.
+func (ip *IPv6) IsPrivate() bool {
+	return ip.IsUniqueLocal()
+}
+
+// IsUniqueLocal returns true if this is a unique local address (fc00::/7).
+func (ip *IPv6) IsUniqueLocal() bool {
+	return ip.netId>>57 == 0xfc>>1
+}
+
+// IsUnspecified returns true if this is the unspecified address (::).
+// The IPv4-mapped address ::ffff:0.0.0.0 is not considered unspecified, as it represents
+// an IPv4 address; use To4().IsUnspecified() to test the embedded IPv4 address instead.
+func (ip *IPv6) IsUnspecified() bool {
+	return ip.IsZero()
+}
+
 // IsZero returns true if this address is "::"
 func (ip *IPv6) IsZero() bool{
 	if ip.netId | ip.hostId == 0{
@@ -120,9 +290,11 @@ func (ip *IPv6) IsZero() bool{
 	return false
 }
 
-// Long returns the IPv6 address as a string in long (uncompressed) format.
+// Long returns the IPv6 address as a string in long (uncompressed) format, ie. all
+// eight groups as 4 hex digits with neither '::' compression nor leading-zero
+// suppression, followed by its zone identifier if it has one.
 func (ip *IPv6) Long() string {
-	return fmt.Sprintf(
+	return ip.withZone(fmt.Sprintf(
 		"%04x:%04x:%04x:%04x:%04x:%04x:%04x:%04x",
 		ip.netId>>48&0xffff,
 		ip.netId>>32&0xffff,
@@ -132,7 +304,13 @@ func (ip *IPv6) Long() string {
 		ip.hostId>>32&0xffff,
 		ip.hostId>>16&0xffff,
 		ip.hostId&0xffff,
-	)
+	))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the 16 bytes of the address
+// in big-endian order (see Bytes), followed by the zone identifier if there is one.
+func (ip *IPv6) MarshalBinary() ([]byte, error) {
+	return append(ip.Bytes(), ip.zone...), nil
 }
 
 // NetId returns the interal uint64 for the network id portion of the address.
@@ -145,7 +323,7 @@ func (ip *IPv6) Next() *IPv6 {
 	if ip.hostId == F64{
 		return nil
 	}
-	return NewIPv6(ip.netId, ip.hostId + 1)
+	return &IPv6{netId: ip.netId, hostId: ip.hostId + 1, zone: ip.zone}
 }
 
 // Prev returns the preceding IPv6 or nil if this is first address of this /64 space.
@@ -153,12 +331,32 @@ func (ip *IPv6) Prev() *IPv6 {
 	if ip.hostId == 0{
 		return nil
 	}
-	return NewIPv6(ip.netId, ip.hostId - 1)
+	return &IPv6{netId: ip.netId, hostId: ip.hostId - 1, zone: ip.zone}
+}
+
+// Scan implements sql.Scanner. It accepts an address in string or []byte form.
+func (ip *IPv6) Scan(src interface{}) error {
+	str, err := scanString(src, "IPv6")
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseIPv6(str)
+	if err != nil {
+		return err
+	}
+	*ip = *parsed
+	return nil
 }
 
 // String returns IPv6 as a string in zero-compressed format (per rfc5952).
+// IPv4-mapped addresses are rendered with the IPv4 address in dotted-quad format (eg. ::ffff:192.168.1.1).
+// The zone identifier, if any, is appended following a '%' (eg. fe80::1%eth0).
 // Use Long() to render in uncompressed format.
 func (ip *IPv6) String() string {
+	if ip.Is4Mapped() {
+		return ip.withZone("::ffff:" + ip.To4().String())
+	}
+
 	hexStr := make([]string, 8, 8)
 	u64 := ip.netId
 	zeroStart, finalStart, finalEnd, consec0 := -1, -1, -1, 0
@@ -182,7 +380,7 @@ func (ip *IPv6) String() string {
 
 			// test for longest consecutive zeros when non-zero encountered or we're at the end
 			if wd != 0 || hexStrI == 7 {
-				if consec0 > finalEnd-finalStart {
+				if consec0 > 1 && consec0 > finalEnd-finalStart { // rfc5952 4.2.2: never compress a single 0 word
 					finalStart = zeroStart
 					finalEnd = finalStart + consec0
 				}
@@ -197,9 +395,21 @@ func (ip *IPv6) String() string {
 	if finalStart != -1 {
 		head := strings.Join(hexStr[:finalStart], ":")
 		tail := strings.Join(hexStr[finalEnd:], ":")
-		return head + "::" + tail
+		return ip.withZone(head + "::" + tail)
+	}
+	return ip.withZone(strings.Join(hexStr, ":"))
+}
+
+// To4 returns the IPv4 address embedded within an IPv4-mapped (::ffff:0:0/96)
+// or 6to4 (2002::/16) address. It will return nil for any other address.
+func (ip *IPv6) To4() *IPv4 {
+	if ip.Is4Mapped() {
+		return NewIPv4(uint32(ip.hostId))
+	}
+	if ip.netId>>48 == 0x2002 {
+		return NewIPv4(uint32(ip.netId >> 16))
 	}
-	return strings.Join(hexStr, ":")
+	return nil
 }
 
 // ToNet returns the IPv6 as a IPv6Net
@@ -207,4 +417,64 @@ func (ip *IPv6) ToNet() *IPv6Net{
 	return initIPv6Net(ip,nil)
 }
 
+// ToNetipAddr returns the IPv6 as a netip.Addr from the standard library, including its zone.
+func (ip *IPv6) ToNetipAddr() netip.Addr {
+	var b [16]byte
+	copy(b[:], ip.Bytes())
+	return netip.AddrFrom16(b).WithZone(ip.zone)
+}
+
+// ToStdIP returns the IPv6 as a 16-byte net.IP from the standard library.
+// The zone identifier, if any, is not included.
+func (ip *IPv6) ToStdIP() stdnet.IP {
+	return stdnet.IP(ip.Bytes())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. See MarshalBinary.
+func (ip *IPv6) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return fmt.Errorf("IPv6 address must be at least 16 bytes. Received %d bytes.", len(data))
+	}
+	parsed, _ := IPv6FromBytes(data[:16])
+	parsed.zone = string(data[16:])
+	*ip = *parsed
+	return nil
+}
+
+// Value implements driver.Valuer. The address is stored in string form.
+func (ip *IPv6) Value() (driver.Value, error) {
+	return ip.String(), nil
+}
+
 func (ip *IPv6) Version() uint{return 6}
+
+// Zone returns the scope zone identifier of this IPv6 (eg. eth0), or an empty string if it has none.
+func (ip *IPv6) Zone() string {
+	return ip.zone
+}
+
+
+// NON EXPORTED
+
+// expandIPv4Tail replaces an IPv4 address in dotted-quad format found in the final
+// group of an IPv6 address with the 2 equivalent groups of hex strings.
+func expandIPv4Tail(groups []string) ([]string, error) {
+	last := len(groups) - 1
+	if !strings.Contains(groups[last], ".") {
+		return groups, nil
+	}
+	ip, err := ParseIPv4(groups[last])
+	if err != nil {
+		return nil, err
+	}
+	tail := []string{strconv.FormatUint(uint64(ip.addr>>16), 16), strconv.FormatUint(uint64(ip.addr&0xffff), 16)}
+	return append(groups[:last:last], tail...), nil
+}
+
+// withZone appends the zone identifier of this IPv6, if any, to the string form of the address.
+func (ip *IPv6) withZone(addr string) string {
+	if ip.zone == "" {
+		return addr
+	}
+	return addr + "%" + ip.zone
+}
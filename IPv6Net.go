@@ -1,10 +1,20 @@
 package netaddr
 
 import (
+	"database/sql/driver"
+	"encoding/binary"
 	"fmt"
+	"math/big"
+	"math/bits"
+	stdnet "net"
+	"net/netip"
 	"strings"
 )
 
+// MaxIPv6SplitCount is the largest count accepted by IPv6Net.Split, which builds its
+// result in full. Use IPv6Net.WalkSubnets or IPv6Net.SubnetIter for larger counts.
+const MaxIPv6SplitCount uint64 = 1 << 20
+
 // IPv6Net represents an IPv6 network.
 type IPv6Net struct {
 	base *IPv6
@@ -44,6 +54,16 @@ func ParseIPv6Net(addr string) (*IPv6Net, error) {
 	return initIPv6Net(ip, m128), nil
 }
 
+// MustParseIPv6Net is like ParseIPv6Net but panics if the string cannot be parsed.
+// It simplifies the safe initialization of global variables and test fixtures.
+func MustParseIPv6Net(addr string) *IPv6Net {
+	parsed, err := ParseIPv6Net(addr)
+	if err != nil {
+		panic(fmt.Sprintf("MustParseIPv6Net(%q): %s", addr, err.Error()))
+	}
+	return parsed
+}
+
 // NewIPv6Net creates a IPv6Net type from a IPv6 and Mask128.
 // If netmask is nil then default to /64 (or /0 for address ::).
 func NewIPv6Net(ip *IPv6, m128 *Mask128) (*IPv6Net, error) {
@@ -54,6 +74,33 @@ func NewIPv6Net(ip *IPv6, m128 *Mask128) (*IPv6Net, error) {
 	return initIPv6Net(ip, m128), nil
 }
 
+// IPv6NetFromStdIPNet creates an IPv6Net type from a net.IPNet of the standard library.
+// The mask must be in canonical form.
+func IPv6NetFromStdIPNet(n *stdnet.IPNet) (*IPv6Net, error) {
+	if n == nil {
+		return nil, fmt.Errorf("Argument n must not be nil.")
+	}
+	ones, bits := n.Mask.Size()
+	if len(n.IP) != 16 || bits != 128 {
+		return nil, fmt.Errorf("Network %s is not a valid IPv6 network.", n)
+	}
+	addr, _ := IPv6FromBytes(n.IP)
+	return initIPv6Net(addr, initMask128(uint(ones))), nil
+}
+
+/*
+ARPA returns the name of the ip6.arpa. reverse DNS zone for this network, containing only
+the nibbles of the network prefix, eg. 2001:db8::/32 becomes 8.b.d.0.1.0.0.2.ip6.arpa.
+
+Reverse zones are delegated on nibble (4 bit) boundaries, so a prefix length which is not
+a multiple of 4 is rounded down to the nearest nibble and the name of the enclosing zone
+is returned, eg. 2001:db8::/30 becomes b.d.0.1.0.0.2.ip6.arpa. The default route returns ip6.arpa.
+*/
+func (net *IPv6Net) ARPA() string {
+	nibbles := net.m128.prefixLen / 4
+	return net.base.ARPA()[(32-nibbles)*2:]
+}
+
 /*
 Cmp compares equality with another IPv6Net. Return:
 	* 1 if this IPv6Net is numerically greater
@@ -89,58 +136,112 @@ func (net *IPv6Net) Contains(ip *IPv6) bool {
 	return false
 }
 
+// ContainsIP implements IPNet. It returns true if ip is an *IPv6 contained by this IPv6Net.
+func (net *IPv6Net) ContainsIP(ip IP) bool {
+	ip6, ok := ip.(*IPv6)
+	return ok && net.Contains(ip6)
+}
+
 // Fill returns a copy of the given IPv6NetList, stripped of
 // any networks which are not subnets of this IPv6Net, and
-// with any missing gaps filled in.
+// with any missing gaps filled in with the fewest possible networks.
 func (net *IPv6Net) Fill(list IPv6NetList) IPv6NetList {
 	var subs IPv6NetList
 	// get rid of non subnets
-	if list != nil && len(list) > 0 {
-		for _, e := range list {
-			isRel, rel := net.Rel(e)
-			if isRel && rel == 1 { // e is a subnet
-				subs = append(subs, e)
-			}
+	for _, e := range list {
+		isRel, rel := net.Rel(e)
+		if isRel && rel == 1 { // e is a subnet
+			subs = append(subs, e)
 		}
-		// discard subnets of subnets & sort
-		subs = subs.discardSubnets().Sort()
-	} else {
+	}
+	if len(subs) == 0 {
 		return subs
 	}
+	// discard subnets of subnets & sort
+	subs = subs.discardSubnets().Sort()
 
-	// fill
+	// fill the gaps before, between and after the subnets
 	var filled IPv6NetList
-	if len(subs) > 0 {
-		// bottom fill if base address is missing
-		cmp, _ := net.base.Cmp(subs[0].base)
-		if cmp != 0 {
-			filled = subs[0].backfill(net.base)
+	cur := net.base
+	for _, sub := range subs {
+		if cmp, _ := cur.Cmp(sub.base); cmp < 0 {
+			filled = append(filled, ipv6RangeNets(cur, ipv6Add(sub.base, F64, F64))...) // sub.base - 1
 		}
-
-		// fill gaps between subnets
-		sib := net.nthNextSib(1)
-		var ceil *IPv6
-		if sib != nil {
-			ceil = sib.base
-		} else {
-			ceil = NewIPv6(F64, F64)
-		}
-		for i := 0; i < len(subs); i += 1 {
-			sub := subs[i]
-			filled = append(filled, sub)
-			// we need to define a limit for this round
-			var limit *IPv6
-			if i+1 < len(subs) {
-				limit = subs[i+1].base
-			} else {
-				limit = ceil
-			}
-			filled = append(filled, sub.fwdFill(limit)...)
+		filled = append(filled, sub)
+		if cur = ipv6Add(sub.LastAddress(), 0, 1); cur.IsZero() { // end of the address space
+			return filled
 		}
 	}
+	if last := net.LastAddress(); !ipv6Greater(cur, last) {
+		filled = append(filled, ipv6RangeNets(cur, last)...)
+	}
 	return filled
 }
 
+// GobDecode implements gob.GobDecoder using the format of UnmarshalBinary.
+func (net *IPv6Net) GobDecode(data []byte) error {
+	return net.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder using the format of MarshalBinary.
+func (net *IPv6Net) GobEncode() ([]byte, error) {
+	return net.MarshalBinary()
+}
+
+// IsDefaultRoute returns true if this is the default route (::/0).
+func (net *IPv6Net) IsDefaultRoute() bool {
+	return net.m128.prefixLen == 0
+}
+
+// IsHostRoute returns true if this is a host route (/128).
+func (net *IPv6Net) IsHostRoute() bool {
+	return net.m128.prefixLen == 128
+}
+
+// IsStrictSubnetOf returns true if this IPv6Net is contained by, but not equal to, other.
+func (net *IPv6Net) IsStrictSubnetOf(other *IPv6Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel == -1
+}
+
+// IsStrictSupernetOf returns true if this IPv6Net contains, but is not equal to, other.
+func (net *IPv6Net) IsStrictSupernetOf(other *IPv6Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel == 1
+}
+
+// IsSubnetOf returns true if this IPv6Net is contained by other. The test is inclusive,
+// so a network is a subnet of itself; use IsStrictSubnetOf to exclude equal networks.
+func (net *IPv6Net) IsSubnetOf(other *IPv6Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel != 1
+}
+
+// IsSupernetOf returns true if this IPv6Net contains other. The test is inclusive,
+// so a network is a supernet of itself; use IsStrictSupernetOf to exclude equal networks.
+func (net *IPv6Net) IsSupernetOf(other *IPv6Net) bool {
+	isRel, rel := net.Rel(other)
+	return isRel && rel != -1
+}
+
+// Key returns a comparable value identifying this IPv6Net, suitable for use as a map key.
+// It holds the 16 bytes of the network address followed by the prefix length, so two
+// networks are equal if and only if their keys are equal. Key does not allocate.
+func (net *IPv6Net) Key() [17]byte {
+	var key [17]byte
+	binary.BigEndian.PutUint64(key[0:8], net.base.netId)
+	binary.BigEndian.PutUint64(key[8:16], net.base.hostId)
+	key[16] = byte(net.m128.prefixLen)
+	return key
+}
+
+// LastAddress returns the last IP address within this network; that is the
+// address with all host bits set. IPv6 has no broadcast address, but this
+// is its equivalent for the purpose of range calculations.
+func (net *IPv6Net) LastAddress() *IPv6 {
+	return NewIPv6(net.base.netId|(net.m128.netIdMask^F64), net.base.hostId|(net.m128.hostIdMask^F64))
+}
+
 // Len returns the number of IP addresses in this network.
 // This is only useful if you have a subnet smaller than a /64 as
 // it will always return 0 for prefixes <= 64.
@@ -148,11 +249,23 @@ func (net *IPv6Net) Len() uint64 {
 	return net.m128.Len()
 }
 
+// LenBig implements IPNet. It returns the number of IP addresses in this network,
+// and unlike Len it is correct for prefixes <= 64.
+func (net *IPv6Net) LenBig() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), 128-net.m128.prefixLen)
+}
+
 // Long returns the network address as a string in long (uncomrpessed) format.
 func (net *IPv6Net) Long() string {
 	return net.base.Long() + net.m128.String()
 }
 
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is the 16 bytes of the
+// network address in big-endian order followed by a single byte holding the prefix length.
+func (net *IPv6Net) MarshalBinary() ([]byte, error) {
+	return append(net.base.Bytes(), byte(net.m128.prefixLen)), nil
+}
+
 // Netmask returns the Mask128 used by the IPv6Net.
 func (net *IPv6Net) Netmask() *Mask128 {
 	return net.m128
@@ -191,6 +304,12 @@ func (net *IPv6Net) Nth(index uint64) *IPv6 {
 	return NewIPv6(net.base.netId, net.base.hostId+index)
 }
 
+// NthBig returns the IP address at the given index. Unlike Nth it works for networks of
+// any prefix length. If index is nil, negative or exceeds the range then return nil.
+func (net *IPv6Net) NthBig(index *big.Int) *IPv6 {
+	return net.nthBig(128, index)
+}
+
 // NthSubnet returns the subnet IPv6Net at the given index.
 // The number of subnets may be determined with the SubnetCount() method.
 // If the range is exceeded  or an invalid prefixLen is provided then return nil.
@@ -203,6 +322,25 @@ func (net *IPv6Net) NthSubnet(prefixLen uint, index uint64) *IPv6Net {
 	return sub0.nthNextSib(index)
 }
 
+// NthSubnetBig returns the subnet IPv6Net at the given index. Unlike NthSubnet it may address
+// any subnet; the number of subnets may be determined with the SubnetCountBig() method.
+// If the range is exceeded, index is nil or negative, or an invalid prefixLen is provided then return nil.
+func (net *IPv6Net) NthSubnetBig(prefixLen uint, index *big.Int) *IPv6Net {
+	if prefixLen <= net.m128.prefixLen || prefixLen > 128 {
+		return nil
+	}
+	ip := net.nthBig(prefixLen, index)
+	if ip == nil {
+		return nil
+	}
+	return &IPv6Net{ip, initMask128(prefixLen)}
+}
+
+// PrefixLen returns the prefix length of the IPv6Net.
+func (net *IPv6Net) PrefixLen() uint {
+	return net.m128.prefixLen
+}
+
 // Prev returns the previous largest consecutive IP network
 // or nil if the start of the address space is reached.
 func (net *IPv6Net) Prev() *IPv6Net {
@@ -239,6 +377,10 @@ be interpreted as:
 	* -1 if this IPv6Net is a subnet of other
 */
 func (net *IPv6Net) Rel(other *IPv6Net) (bool, int) {
+	if other == nil {
+		return false, 0
+	}
+
 	cmp, err := net.base.Cmp(other.base)
 	if err != nil {
 		return false, 0
@@ -273,11 +415,57 @@ func (net *IPv6Net) Resize(prefixLen uint) *IPv6Net{
 	return net
 }
 
+// Scan implements sql.Scanner. It accepts a network in string or []byte form.
+func (net *IPv6Net) Scan(src interface{}) error {
+	str, err := scanString(src, "IPv6Net")
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseIPv6Net(str)
+	if err != nil {
+		return err
+	}
+	*net = *parsed
+	return nil
+}
+
+// Split divides this IPv6Net into count equally sized subnets. count must be a power of 2
+// no greater than MaxIPv6SplitCount, and the resulting subnets may not be longer than /128.
+// The result is built in full, so use WalkSubnets() or SubnetIter() to visit larger
+// numbers of subnets.
+func (net *IPv6Net) Split(count uint64) (IPv6NetList, error) {
+	if count == 0 || count&(count-1) != 0 {
+		return nil, fmt.Errorf("Count %d is not a power of 2.", count)
+	}
+	if count > MaxIPv6SplitCount {
+		return nil, fmt.Errorf("Count %d exceeds the maximum of %d. Use WalkSubnets or SubnetIter instead.", count, MaxIPv6SplitCount)
+	}
+	prefixLen := net.m128.prefixLen + uint(bits.TrailingZeros64(count))
+	if prefixLen > 128 {
+		return nil, fmt.Errorf("Network %s cannot be split into %d subnets.", net, count)
+	}
+	list := make(IPv6NetList, 0, count)
+	net.walkSubnets(prefixLen, func(sub *IPv6Net) bool {
+		list = append(list, sub)
+		return true
+	})
+	return list, nil
+}
+
 // String returns the network address as a string in zero-compressed format.
 func (net *IPv6Net) String() string {
 	return net.base.String() + net.m128.String()
 }
 
+// StringNoHostSuffix behaves like String, but omits the prefix length of /128 networks
+// (eg. 1::1 rather than 1::1/128).
+func (net *IPv6Net) StringNoHostSuffix() string {
+	if net.m128.prefixLen == 128 {
+		return net.base.String()
+	}
+	return net.String()
+}
+
 // SubnetCount returns the number a subnets of a given prefix length that this IPv6Net contains.
 // It will return 0 for invalid requests (ie. bad prefix or prefix is shorter than that of this network).
 // It will also return 0 if the result exceeds the capacity of uint64 (ie. if you want the # of /128 a /8 will hold)
@@ -293,6 +481,28 @@ func (net *IPv6Net) SubnetCount(prefixLen uint) uint64 {
 	return 1 << (prefixLen - net.m128.prefixLen)
 }
 
+// SubnetCountBig returns the number a subnets of a given prefix length that this IPv6Net contains.
+// Unlike SubnetCount() the result is never truncated, however it will still return 0 for invalid
+// requests (ie. bad prefix or prefix is shorter than that of this network).
+func (net *IPv6Net) SubnetCountBig(prefixLen uint) *big.Int {
+	if prefixLen <= net.m128.prefixLen || prefixLen > 128 {
+		return new(big.Int)
+	}
+	return new(big.Int).Lsh(big.NewInt(1), prefixLen-net.m128.prefixLen)
+}
+
+// SubnetIter returns an iterator over the subnets of the given prefix length within this
+// IPv6Net in ascending order. Subnets are generated on demand, so this is suitable for
+// networks holding more subnets than could be built at once. The iterator is empty
+// if prefixLen is invalid (see SubnetCount).
+func (net *IPv6Net) SubnetIter(prefixLen uint) *IPv6NetIter {
+	iter := &IPv6NetIter{parent: net}
+	if prefixLen > net.m128.prefixLen && prefixLen <= 128 {
+		iter.next = initIPv6Net(net.base, initMask128(prefixLen))
+	}
+	return iter
+}
+
 // Summ creates a summary address from this IPv6Net and another or nil if the two networks are incapable of being summarized.
 func (net *IPv6Net) Summ(other *IPv6Net) *IPv6Net {
 	if other == nil || net.m128.prefixLen != other.m128.prefixLen {
@@ -319,51 +529,50 @@ func (net *IPv6Net) Summ(other *IPv6Net) *IPv6Net {
 	return net.Resize(net.m128.prefixLen - 1)
 }
 
-func (ip *IPv6Net) Version() uint{return 6}
-
+// ToNetipPrefix returns the IPv6Net as a netip.Prefix from the standard library.
+func (net *IPv6Net) ToNetipPrefix() netip.Prefix {
+	return netip.PrefixFrom(net.base.ToNetipAddr(), int(net.m128.prefixLen))
+}
 
-// NON EXPORTED
+// ToStdIPNet returns the IPv6Net as a net.IPNet from the standard library.
+func (net *IPv6Net) ToStdIPNet() *stdnet.IPNet {
+	return &stdnet.IPNet{IP: net.base.ToStdIP(), Mask: stdnet.CIDRMask(int(net.m128.prefixLen), 128)}
+}
 
-// backfill generates subnets between this net and the limit address.
-// limit should be < net. will create subnets up to and including limit.
-func (net *IPv6Net) backfill(limit *IPv6) IPv6NetList {
-	var nets IPv6NetList
-	cur := net
-	for {
-		prev := cur.Prev()
-		if prev == nil {
-			break
-		}
-		cmp, _ := prev.base.Cmp(limit)
-		if cmp == -1 {
-			break
-		}
-		nets = append(IPv6NetList{prev}, nets...)
-		cur = prev
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. See MarshalBinary.
+func (net *IPv6Net) UnmarshalBinary(data []byte) error {
+	if len(data) != 17 {
+		return fmt.Errorf("IPv6Net must be exactly 17 bytes. Received %d bytes.", len(data))
 	}
-	return nets
+	ip, _ := IPv6FromBytes(data[:16])
+	m128, err := NewMask128(uint(data[16]))
+	if err != nil {
+		return err
+	}
+	*net = *initIPv6Net(ip, m128)
+	return nil
 }
 
-// fwdFill returns subnets between this net and the limit address.
-// limit should be > net. will create subnets up to limit.
-func (net *IPv6Net) fwdFill(limit *IPv6) IPv6NetList {
-	var nets IPv6NetList
-	cur := net
-	for {
-		next := cur.Next()
-		if next == nil {
-			break
-		}
-		cmp, _ := next.base.Cmp(limit)
-		if cmp >= 0 {
-			break
-		}
-		nets = append(nets, next)
-		cur = next
+// Value implements driver.Valuer. The network is stored in CIDR string form.
+func (net *IPv6Net) Value() (driver.Value, error) {
+	return net.String(), nil
+}
+
+func (ip *IPv6Net) Version() uint{return 6}
+
+// WalkSubnets calls fn for each subnet of the given prefix length within this IPv6Net in
+// ascending order, stopping early if fn returns false. Subnets are generated as they are
+// visited rather than all at once. It does nothing if prefixLen is invalid (see SubnetCount).
+func (net *IPv6Net) WalkSubnets(prefixLen uint, fn func(*IPv6Net) bool) {
+	if prefixLen <= net.m128.prefixLen || prefixLen > 128 {
+		return
 	}
-	return nets
+	net.walkSubnets(prefixLen, fn)
 }
 
+
+// NON EXPORTED
+
 // initIPv6Net initializes a new IPv6Net
 func initIPv6Net(ip *IPv6, m128 *Mask128) *IPv6Net {
 	net := new(IPv6Net)
@@ -415,6 +624,99 @@ func (net *IPv6Net) grow() *IPv6Net {
 	return resized
 }
 
+// ipv6Add returns the IPv6 resulting from adding the 128-bit value hi:lo to ip,
+// wrapping around at the end of the address space.
+func ipv6Add(ip *IPv6, hi, lo uint64) *IPv6 {
+	hostId, carry := bits.Add64(ip.hostId, lo, 0)
+	netId, _ := bits.Add64(ip.netId, hi, carry)
+	return NewIPv6(netId, hostId)
+}
+
+// ipv6Greater returns true if ip is numerically greater than other, ignoring zones.
+func ipv6Greater(ip, other *IPv6) bool {
+	return ip.netId > other.netId || (ip.netId == other.netId && ip.hostId > other.hostId)
+}
+
+// ipv6RangeNets returns the minimal list of networks covering the addresses
+// from first up to and including last.
+func ipv6RangeNets(first, last *IPv6) IPv6NetList {
+	var nets IPv6NetList
+	for !ipv6Greater(first, last) {
+		// the largest block aligned on first
+		size := uint(bits.TrailingZeros64(first.hostId))
+		if first.hostId == 0 {
+			size = 64 + uint(bits.TrailingZeros64(first.netId))
+		}
+		// which does not extend past last, ie. at most log2(last - first + 1)
+		spanLo, borrow := bits.Sub64(last.hostId, first.hostId, 0)
+		spanHi, _ := bits.Sub64(last.netId, first.netId, borrow)
+		spanLo, carry := bits.Add64(spanLo, 1, 0)
+		spanHi, carry = bits.Add64(spanHi, 0, carry)
+		var spanLen uint
+		if carry != 0 { // the whole address space
+			spanLen = 128
+		} else if spanHi != 0 {
+			spanLen = 64 + uint(bits.Len64(spanHi)) - 1
+		} else {
+			spanLen = uint(bits.Len64(spanLo)) - 1
+		}
+		if spanLen < size {
+			size = spanLen
+		}
+		nets = append(nets, &IPv6Net{first, initMask128(128 - size)})
+		if size == 128 {
+			break
+		}
+		var hi, lo uint64
+		if size >= 64 {
+			hi = 1 << (size - 64)
+		} else {
+			lo = 1 << size
+		}
+		next := ipv6Add(first, hi, lo)
+		if next.IsZero() { // end of the address space
+			break
+		}
+		first = next
+	}
+	return nets
+}
+
+// nextWithin returns the network immediately following this one, or nil if that network
+// does not fall within parent. Unlike nthNextSib, the full 128-bit address is incremented.
+func (net *IPv6Net) nextWithin(parent *IPv6Net) *IPv6Net {
+	var netStep, hostStep uint64
+	prefixLen := net.m128.prefixLen
+	if prefixLen == 0 {
+		return nil
+	} else if prefixLen <= 64 {
+		netStep = 1 << (64 - prefixLen)
+	} else {
+		hostStep = 1 << (128 - prefixLen)
+	}
+	hostId, carry := bits.Add64(net.base.hostId, hostStep, 0)
+	netId, carry := bits.Add64(net.base.netId, netStep, carry)
+	ip := NewIPv6(netId, hostId)
+	if carry != 0 || !parent.Contains(ip) {
+		return nil
+	}
+	return &IPv6Net{ip, net.m128}
+}
+
+// nthBig returns the base address of the block of the given prefix length at index within
+// this network, or nil if index is nil, negative or out of range.
+func (net *IPv6Net) nthBig(prefixLen uint, index *big.Int) *IPv6 {
+	if index == nil || index.Sign() < 0 || index.BitLen() > int(prefixLen-net.m128.prefixLen) {
+		return nil
+	}
+	offset := new(big.Int).Lsh(index, 128-prefixLen)
+	lo := new(big.Int).And(offset, new(big.Int).SetUint64(F64)).Uint64()
+	hi := offset.Rsh(offset, 64).Uint64()
+	hostId, carry := bits.Add64(net.base.hostId, lo, 0)
+	netId, _ := bits.Add64(net.base.netId, hi, carry)
+	return NewIPv6(netId, hostId)
+}
+
 // nthNextSib returns the nth next sibling network or nil if address space exceeded.
 func (net *IPv6Net) nthNextSib(nth uint64) *IPv6Net {
 	var netId,hostId uint64
@@ -443,3 +745,14 @@ func (net *IPv6Net) nthNextSib(nth uint64) *IPv6Net {
 	}
 	return &IPv6Net{ip, net.m128}
 }
+
+// walkSubnets calls fn for every subnet of the given prefix length within this network in
+// ascending order, stopping early if fn returns false. prefixLen must not be shorter than
+// the prefix length of this network.
+func (net *IPv6Net) walkSubnets(prefixLen uint, fn func(*IPv6Net) bool) {
+	for sub := initIPv6Net(net.base, initMask128(prefixLen)); sub != nil; sub = sub.nextWithin(net) {
+		if !fn(sub) {
+			return
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package netaddr
+
+// IPv6NetIter iterates over the subnets of an IPv6Net. See IPv6Net.SubnetIter().
+type IPv6NetIter struct {
+	parent *IPv6Net
+	next   *IPv6Net
+}
+
+// Next returns the next subnet, or nil once the iterator is exhausted.
+func (iter *IPv6NetIter) Next() *IPv6Net {
+	cur := iter.next
+	if cur != nil {
+		iter.next = cur.nextWithin(iter.parent)
+	}
+	return cur
+}
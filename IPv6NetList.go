@@ -1,6 +1,7 @@
 package netaddr
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 )
@@ -30,6 +31,16 @@ func (list IPv6NetList) Less(i, j int) bool {
 	return cmp == -1
 }
 
+// MarshalJSON implements json.Marshaler. The list is encoded as an array of
+// CIDR strings, eg. ["fec0::/64","2001:db8::/32"].
+func (list IPv6NetList) MarshalJSON() ([]byte, error) {
+	strs := make([]string, len(list))
+	for i, net := range list {
+		strs[i] = net.String()
+	}
+	return json.Marshal(strs)
+}
+
 // Sort sorts the list using sort.Sort(). Returns itself.
 func (list IPv6NetList) Sort() IPv6NetList {
 	sort.Sort(list)
@@ -55,6 +66,21 @@ func (list IPv6NetList) Summ() IPv6NetList {
 // Swap is used to implement the sort interface
 func (list IPv6NetList) Swap(i, j int) { list[i], list[j] = list[j], list[i] }
 
+// UnmarshalJSON implements json.Unmarshaler. It accepts an array of network
+// strings as produced by MarshalJSON and reports the index of the first invalid entry.
+func (list *IPv6NetList) UnmarshalJSON(data []byte) error {
+	var strs []string
+	if err := json.Unmarshal(data, &strs); err != nil {
+		return err
+	}
+	parsed, err := NewIPv6NetList(strs)
+	if err != nil {
+		return err
+	}
+	*list = parsed
+	return nil
+}
+
 // NON EXPORTED
 
 // discardSubnets returns a sorted copy of the IPv6NetList with
@@ -1,7 +1,9 @@
 package netaddr
 
 import "testing"
+import "encoding/json"
 import "fmt"
+import "strings"
 
 func ExampleNewIPv6NetList() {
 	nets := []string{"1::/64", "2::/64"}
@@ -53,6 +55,41 @@ func Test_NewIPv6NetList(t *testing.T) {
 	}
 }
 
+func Test_IPv6NetList_JSON(t *testing.T) {
+	list, _ := NewIPv6NetList([]string{"fec0::/64", "2001:db8::/32"})
+	b, err := json.Marshal(list)
+	if err != nil || string(b) != `["fec0::/64","2001:db8::/32"]` {
+		t.Errorf("json.Marshal(%v) Result: %s %v", list, b, err)
+	}
+	var parsed IPv6NetList
+	if err := json.Unmarshal(b, &parsed); err != nil || fmt.Sprint(parsed) != fmt.Sprint(list) {
+		t.Errorf("json.Unmarshal(%s) Expect: %v  Result: %v %v", b, list, parsed, err)
+	}
+
+	// as a struct field
+	var cfg struct {
+		Nets IPv6NetList `json:"nets"`
+	}
+	if err := json.Unmarshal([]byte(`{"nets":["fec0::/64","2001:db8::/32"]}`), &cfg); err != nil || fmt.Sprint(cfg.Nets) != fmt.Sprint(list) {
+		t.Errorf("json.Unmarshal() Expect: %v  Result: %v %v", list, cfg.Nets, err)
+	}
+
+	// empty
+	b, _ = json.Marshal(IPv6NetList{})
+	if string(b) != "[]" {
+		t.Errorf("json.Marshal(IPv6NetList{}) Expect: []  Result: %s", b)
+	}
+
+	// errors
+	err = json.Unmarshal([]byte(`["fec0::/64","fec0::/129"]`), &parsed)
+	if err == nil || !strings.Contains(err.Error(), "item index 1") {
+		t.Errorf("json.Unmarshal() Expect: error for item index 1  Result: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`"fec0::/64"`), &parsed); err == nil {
+		t.Errorf("json.Unmarshal() Expect: error for non-array  Result: nil")
+	}
+}
+
 func Test_IPv6NetList_Summ(t *testing.T) {
 	cases := []struct {
 		given  []string
@@ -68,7 +105,23 @@ func Test_IPv6NetList_Summ(t *testing.T) {
 		},
 		{ // mix of out of order, duplicates, non-contiguous, subnet-of
 			[]string{"ff80::/9", "ff10::/12", "ff80::/10", "ff20::/12", "fff0::/16", "fff1::/16", "ff80::/10"},
-			[]string{"ff10::/12", "ff20::/12", "ff80::/9", "fff0::/15"},
+			[]string{"ff10::/12", "ff20::/12", "ff80::/9"}, // fff0::/15 is within ff80::/9
+		},
+		{ // adjacent /33 into a /32
+			[]string{"2001:db8:8000::/33", "2001:db8::/33"},
+			[]string{"2001:db8::/32"},
+		},
+		{ // merges across the /64 boundary
+			[]string{"2001:db8::/65", "2001:db8::8000:0:0:0/65", "2001:db8:0:1::/64"},
+			[]string{"2001:db8::/63"},
+		},
+		{ // merges within the host portion
+			[]string{"2001:db8::/128", "2001:db8::1/128", "2001:db8::2/127", "2001:db8::5/128"},
+			[]string{"2001:db8::/126", "2001:db8::5/128"},
+		},
+		{ // adjacent but not aligned, so no merge
+			[]string{"2001:db8:8000::/33", "2001:db9::/33"},
+			[]string{"2001:db8:8000::/33", "2001:db9::/33"},
 		},
 	}
 
@@ -78,6 +131,10 @@ func Test_IPv6NetList_Summ(t *testing.T) {
 			t.Errorf("%v.Summ() unexpected error: %s", list, err.Error())
 		} else {
 			list = list.Summ()
+			if len(list) != len(c.expect) {
+				t.Errorf("%v.Summ() Expect: %v   Result: %v", c.given, c.expect, list)
+				continue
+			}
 			for i, e := range list {
 				if e.String() != c.expect[i] {
 					t.Errorf("%v.Summ() Expect: %v   Result: %v", c.given, c.expect, list)
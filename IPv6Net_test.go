@@ -1,6 +1,11 @@
 package netaddr
 
 import "testing"
+import "fmt"
+import "math/big"
+import "strings"
+import stdnet "net"
+import "net/netip"
 
 func Test_ParseIPv6Net(t *testing.T) {
 	cases := []struct {
@@ -33,6 +38,42 @@ func Test_ParseIPv6Net(t *testing.T) {
 	}
 }
 
+func Test_MustParseIPv6Net(t *testing.T) {
+	if parsed := MustParseIPv6Net("fe80::1/64"); parsed.String() != "fe80::/64" {
+		t.Errorf("MustParseIPv6Net(fe80::1/64) Expect: fe80::/64  Result: %s", parsed)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "fe80::/129") {
+			t.Errorf("MustParseIPv6Net(fe80::/129) expected a panic naming the input. Result: %v", r)
+		}
+	}()
+	MustParseIPv6Net("fe80::/129")
+}
+
+func Test_IPv6Net_ARPA(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"2001:db8::/32", "8.b.d.0.1.0.0.2.ip6.arpa."},
+		{"2001:db8:abcd:12::/64", "2.1.0.0.d.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa."},
+		{"2001:db8::/30", "b.d.0.1.0.0.2.ip6.arpa."},   // rounded down to /28
+		{"2001:db8::/35", "8.b.d.0.1.0.0.2.ip6.arpa."}, // rounded down to /32
+		{"2001:db8::1/128", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa."},
+		{"::/0", "ip6.arpa."},
+		{"8000::/3", "ip6.arpa."}, // rounded down to /0
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.given)
+		if arpa := net.ARPA(); arpa != c.expect {
+			t.Errorf("%s.ARPA() Expect: %s  Result: %s", c.given, c.expect, arpa)
+		}
+	}
+}
+
 func Test_IPv6Net_Cmp(t *testing.T) {
 	cases := []struct {
 		ip1 string
@@ -65,6 +106,22 @@ func Test_IPv6Net_Contains(t *testing.T) {
 		{"1:8::/29", "1:f::", true},
 		{"1:8::/29", "1:10::", false},
 		{"1:8::/29", "1:7::", false},
+		{"2001:db8:ab00::/40", "2001:db8:abff:ffff:ffff:ffff:ffff:ffff", true},
+		{"2001:db8:ab00::/40", "2001:db8:ac00::", false},
+		{"2001:db8:1::/48", "2001:db8:1:ffff::1", true},
+		{"2001:db8:1::/48", "2001:db8:2::1", false},
+		{"2001:db8:1:ff00::/56", "2001:db8:1:ffff::1", true},
+		{"2001:db8:1:ff00::/56", "2001:db8:1:feff::1", false},
+		{"1::/63", "1:0:0:1::1", true},
+		{"1::/63", "1:0:0:2::", false},
+		{"1::/64", "1::ffff:ffff:ffff:ffff", true},
+		{"1::/64", "1:0:0:1::", false},
+		{"1::8000:0:0:0/65", "1::ffff:ffff:ffff:ffff", true},
+		{"1::8000:0:0:0/65", "1::7fff:ffff:ffff:ffff", false},
+		{"1::8000:0:0:0/65", "2::8000:0:0:0", false},
+		{"1::2/127", "1::3", true},
+		{"1::2/127", "1::4", false},
+		{"1::2/127", "2::2", false},
 	}
 
 	for _, c := range cases {
@@ -76,6 +133,30 @@ func Test_IPv6Net_Contains(t *testing.T) {
 	}
 }
 
+// Test_IPv6Net_Contains_AllPrefixLens flips each bit of an address within networks of
+// every prefix length, which must only leave the network when a netmask bit is flipped.
+func Test_IPv6Net_Contains_AllPrefixLens(t *testing.T) {
+	for _, base := range []*IPv6{NewIPv6(0, 0), NewIPv6(F64, F64), NewIPv6(0x20010db800010002, 0x0003000400050006)} {
+		for prefixLen := uint(0); prefixLen <= 128; prefixLen += 1 {
+			net, _ := NewIPv6Net(base, initMask128(prefixLen))
+			if !net.Contains(base) {
+				t.Errorf("%s.Contains(%s) Expect: true  Result: false", net, base)
+			}
+			for bit := uint(0); bit < 128; bit += 1 {
+				ip := NewIPv6(base.netId, base.hostId)
+				if bit < 64 {
+					ip.netId ^= 1 << (63 - bit)
+				} else {
+					ip.hostId ^= 1 << (127 - bit)
+				}
+				if expect := bit >= prefixLen; net.Contains(ip) != expect {
+					t.Errorf("%s.Contains(%s) Expect: %v  Result: %v", net, ip, expect, !expect)
+				}
+			}
+		}
+	}
+}
+
 func Test_IPv6Net_Fill(t *testing.T) {
 	cases := []struct {
 		net    string
@@ -92,6 +173,32 @@ func Test_IPv6Net_Fill(t *testing.T) {
 			[]string{"ff00::/126", "ff00::/120"},
 			[]string{"ff00::/126", "ff00::4/126", "ff00::8/125", "ff00::10/124", "ff00::20/123", "ff00::40/122"},
 		},
+		{ // no subnets
+			"ff00::/8",
+			[]string{"fe00::/8", "::/0"},
+			[]string{},
+		},
+		{ // gap which is not aligned on its own size
+			"1::/120",
+			[]string{"1::/122", "1::60/123"},
+			[]string{"1::/122", "1::40/123", "1::60/123", "1::80/121"},
+		},
+		{ // gaps crossing the /64 boundary
+			"1::/63",
+			[]string{"1:0:0:0:8000::/65"},
+			[]string{"1::/65", "1::8000:0:0:0/65", "1:0:0:1::/64"},
+		},
+		{ // end of the address space
+			"::/0",
+			[]string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127", "8000::/1"},
+			[]string{"::/1", "8000::/1"},
+		},
+		{
+			"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff0/124",
+			[]string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff4/126"},
+			[]string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff0/126", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff4/126",
+				"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff8/125"},
+		},
 	}
 
 	for _, c := range cases {
@@ -111,6 +218,117 @@ func Test_IPv6Net_Fill(t *testing.T) {
 	}
 }
 
+func Test_IPv6Net_LastAddress(t *testing.T) {
+	cases := []struct {
+		net    string
+		expect string
+	}{
+		{"fe80::/10", "febf:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"2001:db8::/64", "2001:db8::ffff:ffff:ffff:ffff"},
+		{"2001:db8::/96", "2001:db8::ffff:ffff"},
+		{"2001:db8::1/128", "2001:db8::1"},
+		{"::/0", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.net)
+		if last := net.LastAddress().String(); last != c.expect {
+			t.Errorf("%s.LastAddress() Expect: %s  Result: %s", c.net, c.expect, last)
+		}
+	}
+}
+
+func Test_IPv6Net_IsHostRoute_IsDefaultRoute(t *testing.T) {
+	cases := []struct {
+		net          string
+		hostRoute    bool
+		defaultRoute bool
+	}{
+		{"::/0", false, true},
+		{"2001:db8::1/128", true, false},
+		{"::1", true, false},
+		{"2001:db8::/127", false, false},
+		{"::/1", false, false},
+		{"::/128", true, false},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.net)
+		if net.IsHostRoute() != c.hostRoute {
+			t.Errorf("%s.IsHostRoute() Expect: %t  Result: %t", c.net, c.hostRoute, !c.hostRoute)
+		}
+		if net.IsDefaultRoute() != c.defaultRoute {
+			t.Errorf("%s.IsDefaultRoute() Expect: %t  Result: %t", c.net, c.defaultRoute, !c.defaultRoute)
+		}
+	}
+}
+
+func Test_IPv6Net_IsSubnetOf_IsSupernetOf(t *testing.T) {
+	cases := []struct {
+		net         string
+		other       string
+		subnet      bool
+		strictSub   bool
+		supernet    bool
+		strictSuper bool
+	}{
+		{"2001:db8::/48", "2001:db8::/32", true, true, false, false},
+		{"2001:db8::/32", "2001:db8:0:1::1/128", false, false, true, true},
+		{"2001:db8::/64", "2001:db8::/64", true, false, true, false},
+		{"2001:db8::/64", "2001:db8:0:1::/64", false, false, false, false},
+		{"::/0", "ffff::/16", false, false, true, true},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.net)
+		other, _ := ParseIPv6Net(c.other)
+		if res := net.IsSubnetOf(other); res != c.subnet {
+			t.Errorf("%s.IsSubnetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.subnet, res)
+		}
+		if res := net.IsStrictSubnetOf(other); res != c.strictSub {
+			t.Errorf("%s.IsStrictSubnetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.strictSub, res)
+		}
+		if res := net.IsSupernetOf(other); res != c.supernet {
+			t.Errorf("%s.IsSupernetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.supernet, res)
+		}
+		if res := net.IsStrictSupernetOf(other); res != c.strictSuper {
+			t.Errorf("%s.IsStrictSupernetOf(%s) Expect: %v  Result: %v", c.net, c.other, c.strictSuper, res)
+		}
+	}
+
+	net, _ := ParseIPv6Net(cases[0].net)
+	if net.IsSubnetOf(nil) || net.IsSupernetOf(nil) {
+		t.Errorf("%s.IsSubnetOf(nil) and IsSupernetOf(nil) Expect: false", net)
+	}
+}
+
+func Test_IPv6Net_Key(t *testing.T) {
+	cases := []struct {
+		net1  string
+		net2  string
+		equal bool
+	}{
+		{"1::/64", "1::1/64", true},
+		{"1::/64", "1:0:0:0::/64", true},
+		{"1::/64", "1::/65", false},
+		{"1::/64", "1:0:0:1::/64", false},
+		{"::/0", "::/128", false},
+	}
+
+	for _, c := range cases {
+		net1, _ := ParseIPv6Net(c.net1)
+		net2, _ := ParseIPv6Net(c.net2)
+		if equal := net1.Key() == net2.Key(); equal != c.equal {
+			t.Errorf("%s.Key() == %s.Key() Expect: %v  Result: %v", c.net1, c.net2, c.equal, equal)
+		}
+	}
+
+	net := MustParseIPv6Net("1::2/64")
+	if key := net.Key(); key != [17]byte{0, 1, 15: 0, 16: 64} {
+		t.Errorf("%s.Key() Expect: [0 1 0 ... 0 64]  Result: %v", net, key)
+	}
+}
+
 func Test_IPv6Net_Len(t *testing.T) {
 	cases := []struct {
 		net string
@@ -216,6 +434,38 @@ func Test_IPv6Net_Nth(t *testing.T) {
 	}
 }
 
+func Test_IPv6Net_NthBig(t *testing.T) {
+	cases := []struct {
+		given  string
+		nth    string
+		expect string
+	}{
+		{"1::/64", "18446744073709551615", "1::ffff:ffff:ffff:ffff"},
+		{"1::/64", "18446744073709551616", ""},
+		{"1::/16", "18446744073709551616", "1:0:0:1::"},
+		{"::/0", "340282366920938463463374607431768211455", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"::/127", "2", ""},
+		{"::/127", "-1", ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.given)
+		index, _ := new(big.Int).SetString(c.nth, 10)
+		nth := net.NthBig(index)
+		if nth == nil {
+			if c.expect != "" {
+				t.Errorf("%s.NthBig(%s) Expect: %s  Result: nil", c.given, c.nth, c.expect)
+			}
+		} else if nth.String() != c.expect {
+			t.Errorf("%s.NthBig(%s) Expect: %s  Result: %s", c.given, c.nth, c.expect, nth)
+		}
+	}
+
+	if nth := MustParseIPv6Net("1::/64").NthBig(nil); nth != nil {
+		t.Errorf("1::/64.NthBig(nil) Expect: nil  Result: %s", nth)
+	}
+}
+
 func Test_IPv6Net_NthSubnet(t *testing.T) {
 	cases := []struct {
 		given  string
@@ -240,6 +490,37 @@ func Test_IPv6Net_NthSubnet(t *testing.T) {
 	}
 }
 
+func Test_IPv6Net_NthSubnetBig(t *testing.T) {
+	cases := []struct {
+		given  string
+		prefix uint
+		nth    string
+		expect string
+	}{
+		{"1::/24", 30, "0", "1::/30"},
+		{"1::/24", 30, "63", "1:fc::/30"},
+		{"1::/24", 30, "64", ""},
+		{"::/0", 128, "340282366920938463463374607431768211455", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff/128"},
+		{"2001:db8::/32", 112, "18446744073709551617", "2001:db8:1::1:0/112"},
+		{"1::/24", 24, "0", ""},
+		{"1::/24", 129, "0", ""},
+		{"1::/24", 30, "-1", ""},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.given)
+		index, _ := new(big.Int).SetString(c.nth, 10)
+		nth := net.NthSubnetBig(c.prefix, index)
+		if nth == nil {
+			if c.expect != "" {
+				t.Errorf("%s.NthSubnetBig(%d,%s) Expect: %s  Result: nil", c.given, c.prefix, c.nth, c.expect)
+			}
+		} else if nth.String() != c.expect {
+			t.Errorf("%s.NthSubnetBig(%d,%s) Expect: %s  Result: %s", c.given, c.prefix, c.nth, c.expect, nth)
+		}
+	}
+}
+
 func Test_IPv6Net_Prev(t *testing.T) {
 	cases := []struct {
 		net  string
@@ -344,6 +625,136 @@ func Test_IPv6Net_Resize(t *testing.T) {
 	}
 }
 
+func Test_IPv6Net_Split(t *testing.T) {
+	cases := []struct {
+		net    string
+		count  uint64
+		expect []string
+	}{
+		{"fec0::/64", 1, []string{"fec0::/64"}},
+		{"fec0::/63", 4, []string{"fec0::/65", "fec0::8000:0:0:0/65", "fec0:0:0:1::/65", "fec0:0:0:1:8000::/65"}},
+		{"fec0::/16", 2, []string{"fec0::/17", "fec0:8000::/17"}},
+		{"::/0", 2, []string{"::/1", "8000::/1"}},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffc/126", 4, []string{
+			"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffc/128", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffd/128",
+			"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/128", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff/128"}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.net)
+		list, err := net.Split(c.count)
+		if err != nil {
+			t.Errorf("%s.Split(%d) unexpected error: %s", c.net, c.count, err)
+			continue
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.Split(%d) Expect: %v  Result: %v", c.net, c.count, c.expect, list)
+		}
+	}
+
+	// errors
+	net, _ := ParseIPv6Net("::/126")
+	for _, count := range []uint64{0, 3, 6, 8} {
+		if _, err := net.Split(count); err == nil {
+			t.Errorf("%s.Split(%d) Expect: error  Result: nil", net, count)
+		}
+	}
+
+	// counts too large to build in full
+	net, _ = ParseIPv6Net("::/0")
+	for _, count := range []uint64{MaxIPv6SplitCount << 1, 1 << 32, 1 << 63} {
+		if list, err := net.Split(count); err == nil || list != nil {
+			t.Errorf("%s.Split(%d) Expect: error  Result: %d subnets", net, count, len(list))
+		}
+	}
+	if list, err := net.Split(MaxIPv6SplitCount); err != nil || uint64(len(list)) != MaxIPv6SplitCount {
+		t.Errorf("%s.Split(%d) Expect: %d subnets  Result: %d, %v", net, MaxIPv6SplitCount, MaxIPv6SplitCount, len(list), err)
+	}
+}
+
+func Test_IPv6Net_StringNoHostSuffix(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"1::1", "1::/64"}, // parsed as a /64
+		{"1::1/128", "1::1"},
+		{"1::/127", "1::/127"},
+		{"fec0::1/10", "fec0::/10"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.given)
+		if str := net.StringNoHostSuffix(); str != c.expect {
+			t.Errorf("%s.StringNoHostSuffix() Expect: %s  Result: %s", c.given, c.expect, str)
+		}
+	}
+}
+
+func Test_IPv6Net_SubnetCountBig(t *testing.T) {
+	cases := []struct {
+		net       string
+		prefixLen uint
+		expect    string
+	}{
+		{"fec0::/64", 65, "2"},
+		{"fec0::/64", 128, "18446744073709551616"},
+		{"::/0", 128, "340282366920938463463374607431768211456"},
+		{"::/0", 1, "2"},
+		{"fec0::/64", 64, "0"},
+		{"fec0::/64", 129, "0"},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.net)
+		expect, _ := new(big.Int).SetString(c.expect, 10)
+		if count := net.SubnetCountBig(c.prefixLen); count.Cmp(expect) != 0 {
+			t.Errorf("%s.SubnetCountBig(%d) Expect: %s  Result: %s", c.net, c.prefixLen, expect, count)
+		}
+	}
+}
+
+func Test_IPv6Net_SubnetIter(t *testing.T) {
+	cases := []struct {
+		net       string
+		prefixLen uint
+		expect    []string
+	}{
+		// crosses the /64 boundary
+		{"fec0::/63", 64, []string{"fec0::/64", "fec0:0:0:1::/64"}},
+		{"fec0:0:0:1::/64", 66, []string{"fec0:0:0:1::/66", "fec0:0:0:1:4000::/66", "fec0:0:0:1:8000::/66", "fec0:0:0:1:c000::/66"}},
+		{"fec0::/63", 65, []string{"fec0::/65", "fec0::8000:0:0:0/65", "fec0:0:0:1::/65", "fec0:0:0:1:8000::/65"}},
+		// end of address space
+		{"ffff::/15", 16, []string{"fffe::/16", "ffff::/16"}},
+		// invalid prefix
+		{"fec0::/64", 64, []string{}},
+		{"fec0::/64", 129, []string{}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.net)
+		iter := net.SubnetIter(c.prefixLen)
+		list := IPv6NetList{}
+		for sub := iter.Next(); sub != nil; sub = iter.Next() {
+			list = append(list, sub)
+		}
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.SubnetIter(%d) Expect: %v  Result: %v", c.net, c.prefixLen, c.expect, list)
+		}
+		if iter.Next() != nil {
+			t.Errorf("%s.SubnetIter(%d) Expect: nil after exhaustion", c.net, c.prefixLen)
+		}
+	}
+
+	// huge ranges may be partially consumed
+	net, _ := ParseIPv6Net("::/0")
+	iter := net.SubnetIter(128)
+	iter.Next()
+	if sub := iter.Next(); sub.String() != "::1/128" {
+		t.Errorf("%s.SubnetIter(128) Expect: ::1/128  Result: %s", net, sub)
+	}
+}
+
 func Test_IPv6Net_SubnetCount(t *testing.T) {
 	cases := []struct {
 		net    string
@@ -403,3 +814,70 @@ func Test_IPv6Net_Summ(t *testing.T) {
 		}
 	}
 }
+
+func Test_IPv6Net_WalkSubnets(t *testing.T) {
+	cases := []struct {
+		net       string
+		prefixLen uint
+		stopAfter int
+		expect    []string
+	}{
+		{"fec0::/63", 64, -1, []string{"fec0::/64", "fec0:0:0:1::/64"}},
+		{"::/0", 128, 3, []string{"::/128", "::1/128", "::2/128"}},
+		{"fec0::/64", 64, -1, []string{}},
+		{"fec0::/64", 129, -1, []string{}},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPv6Net(c.net)
+		list := IPv6NetList{}
+		net.WalkSubnets(c.prefixLen, func(sub *IPv6Net) bool {
+			list = append(list, sub)
+			return len(list) != c.stopAfter
+		})
+		if fmt.Sprint(list) != fmt.Sprint(c.expect) {
+			t.Errorf("%s.WalkSubnets(%d) Expect: %v  Result: %v", c.net, c.prefixLen, c.expect, list)
+		}
+	}
+}
+
+func Test_IPv6Net_Std(t *testing.T) {
+	net, _ := ParseIPv6Net("2001:db8::/32")
+	_, expect, _ := stdnet.ParseCIDR("2001:db8::/32")
+	if std := net.ToStdIPNet(); std.String() != expect.String() || !std.IP.Equal(expect.IP) {
+		t.Errorf("%s.ToStdIPNet() Expect: %s  Result: %s", net, expect, std)
+	}
+	if prefix := net.ToNetipPrefix(); prefix != netip.MustParsePrefix("2001:db8::/32") {
+		t.Errorf("%s.ToNetipPrefix() Expect: 2001:db8::/32  Result: %s", net, prefix)
+	}
+
+	converted, err := IPv6NetFromStdIPNet(expect)
+	if err != nil || converted.String() != "2001:db8::/32" {
+		t.Errorf("IPv6NetFromStdIPNet(%s) Expect: 2001:db8::/32  Result: %s %v", expect, converted, err)
+	}
+	_, other, _ := stdnet.ParseCIDR("10.0.0.0/8")
+	for _, n := range []*stdnet.IPNet{nil, other, {IP: expect.IP, Mask: stdnet.IPMask{0xff, 0, 0xff, 0}}} {
+		if _, err := IPv6NetFromStdIPNet(n); err == nil {
+			t.Errorf("IPv6NetFromStdIPNet(%v) Expect: error  Result: nil", n)
+		}
+	}
+}
+
+func Test_IPv6Net_SQL(t *testing.T) {
+	net, _ := ParseIPv6Net("2001:db8::/32")
+	if v, err := net.Value(); err != nil || v != "2001:db8::/32" {
+		t.Errorf("%s.Value() Expect: 2001:db8::/32  Result: %v %v", net, v, err)
+	}
+
+	for _, src := range []interface{}{"fe80::/64", []byte("fe80::/64")} {
+		scanned := new(IPv6Net)
+		if err := scanned.Scan(src); err != nil || scanned.String() != "fe80::/64" {
+			t.Errorf("Scan(%v) Expect: fe80::/64  Result: %s %v", src, scanned, err)
+		}
+	}
+	for _, src := range []interface{}{"2001:db8::/129", 1, nil} {
+		if err := new(IPv6Net).Scan(src); err == nil {
+			t.Errorf("Scan(%v) Expect: error  Result: nil", src)
+		}
+	}
+}
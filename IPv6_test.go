@@ -1,6 +1,28 @@
 package netaddr
 
 import "testing"
+import "fmt"
+import "strings"
+import stdnet "net"
+import "net/netip"
+
+func ExampleIPv6_NetId() {
+	// the integer form round-trips through NetId()/HostId() and NewIPv6()
+	ip, _ := ParseIPv6("2001:db8::1")
+	netId, hostId := ip.NetId(), ip.HostId()
+	fmt.Printf("%#x %#x %s\n", netId, hostId, NewIPv6(netId, hostId+1))
+	// Output: 0x20010db800000000 0x1 2001:db8::2
+}
+
+func ExampleIPv6_Long() {
+	// Long() is the fully expanded counterpart of the compressed String() form
+	ip, _ := ParseIPv6("2001:db8::1")
+	fmt.Println(ip.Long())
+	fmt.Println(ip)
+	// Output:
+	// 2001:0db8:0000:0000:0000:0000:0000:0001
+	// 2001:db8::1
+}
 
 func Test_ParseIPv6(t *testing.T) {
 	cases := []struct {
@@ -12,7 +34,13 @@ func Test_ParseIPv6(t *testing.T) {
 		{" :: ", 0, 0, false},
 		{"::0", 0, 0, false},
 		{"fe80::", 0xfe80000000000000, 0, false},
-		//{"::ffff:192.168.1.1",0,0xffffc0a80101,false}, // ipv4 mapped
+		{"::ffff:192.168.1.1", 0, 0xffffc0a80101, false}, // ipv4 mapped
+		{"0:0:0:0:0:ffff:192.168.1.1", 0, 0xffffc0a80101, false},
+		{"64:ff9b::10.0.0.1", 0x0064ff9b00000000, 0x0a000001, false},
+		{"::ffff:192.168.1.256", 0, 0, true},
+		{"::ffff:192.168.1", 0, 0, true},
+		{"192.168.1.1", 0, 0, true},
+		{"1:2:3:4:5:6:7:1.2.3.4", 0, 0, true},
 		{"fe80::1::", 0, 0, true},
 		{"::fe80::", 0, 0, true},
 		{"0:0:0:0:0:0:0:0:1", 0, 0, true},
@@ -34,6 +62,10 @@ func Test_ParseIPv6(t *testing.T) {
 		{"::1:2:3:4:5:6:7", 0x0000000100020003, 0x0004000500060007, false},
 		{"fec0", 0, 0, true},
 		{"fec0:::1", 0, 0, true},
+		{"fe80::1%eth0", 0xfe80000000000000, 1, false}, // zone
+		{"::%1", 0, 0, false},
+		{"fe80::1%", 0, 0, true},
+		{"%eth0", 0, 0, true},
 	}
 
 	for _, c := range cases {
@@ -56,6 +88,168 @@ func Test_ParseIPv6(t *testing.T) {
 	}
 }
 
+func Test_MustParseIPv6(t *testing.T) {
+	if parsed := MustParseIPv6("fe80::1"); parsed.String() != "fe80::1" {
+		t.Errorf("MustParseIPv6(fe80::1) Expect: fe80::1  Result: %s", parsed)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "fe80::1::") {
+			t.Errorf("MustParseIPv6(fe80::1::) expected a panic naming the input. Result: %v", r)
+		}
+	}()
+	MustParseIPv6("fe80::1::")
+}
+
+func Test_CanonicalizeIPv6(t *testing.T) {
+	cases := []struct {
+		given     string
+		expect    string
+		expectErr bool
+	}{
+		{"2001:DB8::1", "2001:db8::1", false},
+		{"2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1", false},
+		{"2001:db8:0:0::1", "2001:db8::1", false},
+		{"2001:Db8::0:1", "2001:db8::1", false},
+		{" FE80::0001%eth0 ", "fe80::1%eth0", false},
+		{"0:0:0:0:0:FFFF:C0A8:0101", "::ffff:192.168.1.1", false},
+		{"2001:db8::1::", "", true},
+		{"2001:gb8::", "", true},
+	}
+
+	for _, c := range cases {
+		canonical, err := CanonicalizeIPv6(c.given)
+		if err != nil {
+			if !c.expectErr {
+				t.Errorf("CanonicalizeIPv6(%s) unexpected error: %s", c.given, err)
+			}
+			continue
+		}
+		if c.expectErr {
+			t.Errorf("CanonicalizeIPv6(%s) expected error but none raised", c.given)
+			continue
+		}
+		if canonical != c.expect {
+			t.Errorf("CanonicalizeIPv6(%s) Expect: %s  Result: %s", c.given, c.expect, canonical)
+		}
+	}
+}
+
+func Test_IPv6FromBytes(t *testing.T) {
+	cases := []struct {
+		given     []byte
+		expect    string
+		expectErr bool
+	}{
+		{[]byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, "2001:db8::1", false},
+		{[]byte{0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}, "fe80::1234:5678:9abc:def0", false},
+		{make([]byte, 16), "::", false},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", false},
+		{make([]byte, 15), "", true},
+		{make([]byte, 17), "", true},
+		{[]byte{192, 168, 1, 1}, "", true},
+	}
+
+	for _, c := range cases {
+		ip, err := IPv6FromBytes(c.given)
+		if err != nil {
+			if !c.expectErr {
+				t.Errorf("IPv6FromBytes(%v) unexpected error: %s", c.given, err)
+			} else if !strings.Contains(err.Error(), fmt.Sprintf("Received %d bytes", len(c.given))) {
+				t.Errorf("IPv6FromBytes(%v) error does not state the received length: %s", c.given, err)
+			}
+			continue
+		}
+		if c.expectErr {
+			t.Errorf("IPv6FromBytes(%v) expected error but none raised", c.given)
+			continue
+		}
+		if ip.String() != c.expect {
+			t.Errorf("IPv6FromBytes(%v) Expect: %s  Result: %s", c.given, c.expect, ip)
+		}
+		if fmt.Sprint(ip.Bytes()) != fmt.Sprint(c.given) {
+			t.Errorf("%s.Bytes() Expect: %v  Result: %v", ip, c.given, ip.Bytes())
+		}
+	}
+}
+
+func Test_IPv6_ARPA(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa."},
+		{"::", "0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa."},
+		{"fe80::abcd:1234%eth0", "4.3.2.1.d.c.b.a.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.e.f.ip6.arpa."},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "e.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.f.ip6.arpa."},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv6(c.given)
+		if arpa := ip.ARPA(); arpa != c.expect {
+			t.Errorf("%s.ARPA() Expect: %s  Result: %s", c.given, c.expect, arpa)
+		}
+	}
+}
+
+func Test_IPv6_Bit(t *testing.T) {
+	ip, _ := ParseIPv6("8000:0:0:1::5")
+	cases := []struct {
+		pos    uint
+		expect int
+	}{
+		{0, 1},
+		{1, 0},
+		{63, 1},
+		{64, 0},
+		{125, 1},
+		{126, 0},
+		{127, 1},
+		{128, 0},
+	}
+
+	for _, c := range cases {
+		if bit := ip.Bit(c.pos); bit != c.expect {
+			t.Errorf("%s.Bit(%d) Expect: %d  Result: %d", ip, c.pos, c.expect, bit)
+		}
+	}
+}
+
+func Test_IPv6_CommonPrefixLen(t *testing.T) {
+	cases := []struct {
+		ip     string
+		other  string
+		expect uint
+	}{
+		{"2001:db8::1", "2001:db8::1", 128},
+		{"2001:db8::", "2001:db8::1", 127},
+		{"2001:db8::", "2001:db8::8000:0:0:0", 64},
+		{"2001:db8::", "2001:db8:0:1::", 63},
+		{"2001:db8::", "2001:db9::", 31},
+		{"::", "8000::", 0},
+		{"fe80::1%eth0", "fe80::1%eth1", 128},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv6(c.ip)
+		other, _ := ParseIPv6(c.other)
+		if l := ip.CommonPrefixLen(other); l != c.expect {
+			t.Errorf("%s.CommonPrefixLen(%s) Expect: %d  Result: %d", c.ip, c.other, c.expect, l)
+		}
+		for pos := uint(0); pos < c.expect; pos++ {
+			if ip.Bit(pos) != other.Bit(pos) {
+				t.Errorf("%s.Bit(%d) differs from %s within the common prefix", c.ip, pos, c.other)
+			}
+		}
+	}
+
+	ip, _ := ParseIPv6("::1")
+	if l := ip.CommonPrefixLen(nil); l != 0 {
+		t.Errorf("%s.CommonPrefixLen(nil) Expect: 0  Result: %d", ip, l)
+	}
+}
+
 func Test_IPv6_Cmp(t *testing.T) {
 	cases := []struct {
 		ip1 string
@@ -68,6 +262,16 @@ func Test_IPv6_Cmp(t *testing.T) {
 		{"1::", "2::", -1}, // netId numerically less
 		{"2::", "1::", 1},  // netId numerically greater
 		{"1::", "1::", 0},  // netId eq
+		{"fe80::1%eth0", "fe80::1%eth1", -1}, // zone is a tiebreaker
+		{"fe80::1%eth0", "fe80::1", 1},
+		{"fe80::1%eth0", "fe80::1%eth0", 0},
+		{"fe80::1%eth1", "fe80::2%eth0", -1}, // address takes precedence over zone
+		{"2001:db8::1:0:0:1", "2001:db8::1:0:0:2", -1}, // same netId, hostId differs only in low bits
+		{"2001:db8::8000:0:0:0", "2001:db8::7fff:ffff:ffff:ffff", 1}, // same netId, hostId differs in high bit
+		{"2001:db8:0:1::", "2001:db8:0:0:ffff:ffff:ffff:ffff", 1},    // adjacent /64s, netId takes precedence
+		{"2001:db8:0:0:ffff:ffff:ffff:ffff", "2001:db8:0:1::", -1},
+		{"8000::", "7fff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", 1}, // high bit of netId
+		{"2001:db8::ffff:ffff:ffff:ffff", "2001:db8::ffff:ffff:ffff:ffff", 0},
 	}
 
 	for _, c := range cases {
@@ -80,6 +284,118 @@ func Test_IPv6_Cmp(t *testing.T) {
 	}
 }
 
+func Test_IPv6_Is4Mapped(t *testing.T) {
+	cases := []struct {
+		given  string
+		mapped bool
+		v4     string
+	}{
+		{"::ffff:c0a8:101", true, "192.168.1.1"},
+		{"::ffff:0:0", true, "0.0.0.0"},
+		{"::c0a8:101", false, ""},
+		{"1::ffff:c0a8:101", false, ""},
+		{"2002:a00:1::", false, "10.0.0.1"},
+		{"fe80::1", false, ""},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv6(c.given)
+		if ip.Is4Mapped() != c.mapped {
+			t.Errorf("%s.Is4Mapped() Expect: %t  Result: %t", c.given, c.mapped, !c.mapped)
+		}
+		v4 := ip.To4()
+		if v4 == nil {
+			if c.v4 != "" {
+				t.Errorf("%s.To4() Expect: %s  Result: nil", c.given, c.v4)
+			}
+		} else if v4.String() != c.v4 {
+			t.Errorf("%s.To4() Expect: %s  Result: %s", c.given, c.v4, v4)
+		}
+	}
+}
+
+func Test_IPv6_Classifiers(t *testing.T) {
+	cases := []struct {
+		given         string
+		linkLocal     bool
+		uniqueLocal   bool
+		multicast     bool
+		loopback      bool
+		unspecified   bool
+		globalUnicast bool
+	}{
+		{"fe80::1", true, false, false, false, false, false},
+		{"febf:ffff::", true, false, false, false, false, false},
+		{"fec0::1", false, false, false, false, false, true},
+		{"fd00::1", false, true, false, false, false, true},
+		{"fc00::", false, true, false, false, false, true},
+		{"fe00::", false, false, false, false, false, true},
+		{"ff02::1", false, false, true, false, false, false},
+		{"::1", false, false, false, true, false, false},
+		{"::", false, false, false, false, true, false},
+		{"::ffff:0.0.0.0", false, false, false, false, false, true}, // ipv4 mapped, see To4()
+		{"2001:db8::1", false, false, false, false, false, true},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv6(c.given)
+		if ip.IsLinkLocal() != c.linkLocal {
+			t.Errorf("%s.IsLinkLocal() Expect: %t  Result: %t", c.given, c.linkLocal, !c.linkLocal)
+		}
+		if ip.IsUniqueLocal() != c.uniqueLocal {
+			t.Errorf("%s.IsUniqueLocal() Expect: %t  Result: %t", c.given, c.uniqueLocal, !c.uniqueLocal)
+		}
+		if ip.IsMulticast() != c.multicast {
+			t.Errorf("%s.IsMulticast() Expect: %t  Result: %t", c.given, c.multicast, !c.multicast)
+		}
+		if ip.IsLoopback() != c.loopback {
+			t.Errorf("%s.IsLoopback() Expect: %t  Result: %t", c.given, c.loopback, !c.loopback)
+		}
+		if ip.IsUnspecified() != c.unspecified {
+			t.Errorf("%s.IsUnspecified() Expect: %t  Result: %t", c.given, c.unspecified, !c.unspecified)
+		}
+		if ip.IsGlobalUnicast() != c.globalUnicast {
+			t.Errorf("%s.IsGlobalUnicast() Expect: %t  Result: %t", c.given, c.globalUnicast, !c.globalUnicast)
+		}
+	}
+}
+
+func Test_IPv6_IsUnspecified_Mapped(t *testing.T) {
+	ip, _ := ParseIPv6("::ffff:0.0.0.0")
+	if ip.IsUnspecified() {
+		t.Errorf("%s.IsUnspecified() Expect: false  Result: true", ip)
+	}
+	if !ip.To4().IsUnspecified() {
+		t.Errorf("%s.To4().IsUnspecified() Expect: true  Result: false", ip)
+	}
+}
+
+func Test_IPv6_IsPrivate_IsDocumentation(t *testing.T) {
+	cases := []struct {
+		given         string
+		private       bool
+		documentation bool
+	}{
+		{"fc00::1", true, false},
+		{"fdff:ffff::", true, false},
+		{"fe00::", false, false},
+		{"2001:db8::1", false, true},
+		{"2001:db8:ffff:ffff::", false, true},
+		{"2001:db9::", false, false},
+		{"2001:db7:ffff::", false, false},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv6(c.given)
+		if ip.IsPrivate() != c.private {
+			t.Errorf("%s.IsPrivate() Expect: %t  Result: %t", c.given, c.private, !c.private)
+		}
+		if ip.IsDocumentation() != c.documentation {
+			t.Errorf("%s.IsDocumentation() Expect: %t  Result: %t", c.given, c.documentation, !c.documentation)
+		}
+	}
+}
+
 func Test_IPv6_Long(t *testing.T) {
 	cases := []struct {
 		given  string
@@ -88,6 +404,10 @@ func Test_IPv6_Long(t *testing.T) {
 		{"::", "0000:0000:0000:0000:0000:0000:0000:0000"},
 		{"1::", "0001:0000:0000:0000:0000:0000:0000:0000"},
 		{"1000::", "1000:0000:0000:0000:0000:0000:0000:0000"},
+		{"2001:db8::1", "2001:0db8:0000:0000:0000:0000:0000:0001"},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"::ffff:10.0.0.1", "0000:0000:0000:0000:0000:ffff:0a00:0001"},
+		{"fe80::1%eth0", "fe80:0000:0000:0000:0000:0000:0000:0001%eth0"},
 	}
 
 	for _, c := range cases {
@@ -121,6 +441,15 @@ func Test_IPv6_String(t *testing.T) {
 		{"1:0:0:0:1:0:0:1", "1::1:0:0:1"},
 		{"1:0:0:0:0:1:0:1", "1::1:0:1"},
 		{"1:0:0:0:0:0:1:1", "1::1:1"},
+
+		{"::ffff:c0a8:101", "::ffff:192.168.1.1"}, // ipv4 mapped
+		{"::ffff:0.0.0.0", "::ffff:0.0.0.0"},
+		{"::fffe:c0a8:101", "::fffe:c0a8:101"},
+		{"1::ffff:c0a8:101", "1::ffff:c0a8:101"},
+
+		{"fe80::1%eth0", "fe80::1%eth0"}, // zone
+		{"FE80:0:0:0:0:0:0:1%Eth0", "fe80::1%Eth0"},
+		{"::ffff:192.168.1.1%en0", "::ffff:192.168.1.1%en0"},
 	}
 
 	for _, c := range cases {
@@ -132,6 +461,38 @@ func Test_IPv6_String(t *testing.T) {
 	}
 }
 
+func Test_IPv6_String_RFC5952(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		// 4.1 leading zeros must be suppressed
+		{"2001:0db8:aaaa:bbbb:cccc:dddd:eeee:0001", "2001:db8:aaaa:bbbb:cccc:dddd:eeee:1"},
+		{"2001:db8:0:0:0:0:2:1", "2001:db8::2:1"},
+		// 4.2.1 "::" must be used to its maximum capability
+		{"2001:db8:0:0:0:0:0:1", "2001:db8::1"},
+		// 4.2.2 "::" must not be used to shorten just one 16-bit 0 field
+		{"2001:db8:0:1:1:1:1:1", "2001:db8:0:1:1:1:1:1"},
+		{"0:1:1:1:1:1:1:1", "0:1:1:1:1:1:1:1"},
+		{"1:1:1:1:1:1:1:0", "1:1:1:1:1:1:1:0"},
+		{"1:0:1:0:1:0:1:0", "1:0:1:0:1:0:1:0"},
+		// 4.2.3 the longest run must be shortened, or the first of equal runs
+		{"2001:0:0:1:0:0:0:1", "2001:0:0:1::1"},
+		{"2001:db8:0:0:1:0:0:1", "2001:db8::1:0:0:1"},
+		{"2001:db8:0:0:aaaa:0:0:1", "2001:db8::aaaa:0:0:1"},
+		{"1:0:0:1:1:0:0:1", "1::1:1:0:0:1"},
+		// 4.3 lowercase
+		{"2001:DB8::AAAA:1", "2001:db8::aaaa:1"},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv6(c.given)
+		if short := ip.String(); short != c.expect {
+			t.Errorf("%s.String() Expect: %s  Result: %s", c.given, c.expect, short)
+		}
+	}
+}
+
 func Test_Ipv6_ToNet(t *testing.T) {
 	ip, _ := ParseIPv6("1::")
 	net, _ := ParseIPv6Net("1::")
@@ -140,3 +501,62 @@ func Test_Ipv6_ToNet(t *testing.T) {
 		t.Errorf("%s.ToNet() Expect: %s  Result: %s", ip, net, ip.ToNet())
 	}
 }
+
+func Test_IPv6_Zone(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect string
+	}{
+		{"fe80::1%eth0", "eth0"},
+		{"fe80::1%25", "25"},
+		{"fe80::1", ""},
+	}
+
+	for _, c := range cases {
+		ip, _ := ParseIPv6(c.given)
+		if ip.Zone() != c.expect {
+			t.Errorf("%s.Zone() Expect: %s  Result: %s", c.given, c.expect, ip.Zone())
+		}
+	}
+
+	// zone is preserved by Next/Prev but not by networks
+	ip, _ := ParseIPv6("fe80::1%eth0")
+	if next := ip.Next(); next.String() != "fe80::2%eth0" {
+		t.Errorf("%s.Next() Expect: fe80::2%%eth0  Result: %s", ip, next)
+	}
+	if prev := ip.Prev(); prev.String() != "fe80::%eth0" {
+		t.Errorf("%s.Prev() Expect: fe80::%%eth0  Result: %s", ip, prev)
+	}
+	if net := ip.ToNet(); net.String() != "fe80::/64" {
+		t.Errorf("%s.ToNet() Expect: fe80::/64  Result: %s", ip, net)
+	}
+}
+
+func Test_IPv6_Std(t *testing.T) {
+	ip, _ := ParseIPv6("fe80::1%eth0")
+	if std := ip.ToStdIP(); !std.Equal(stdnet.ParseIP("fe80::1")) {
+		t.Errorf("%s.ToStdIP() Expect: fe80::1  Result: %v", ip, std)
+	}
+	if addr := ip.ToNetipAddr(); addr != netip.MustParseAddr("fe80::1%eth0") {
+		t.Errorf("%s.ToNetipAddr() Expect: fe80::1%%eth0  Result: %v", ip, addr)
+	}
+}
+
+func Test_IPv6_SQL(t *testing.T) {
+	ip, _ := ParseIPv6("2001:db8::1")
+	if v, err := ip.Value(); err != nil || v != "2001:db8::1" {
+		t.Errorf("%s.Value() Expect: 2001:db8::1  Result: %v %v", ip, v, err)
+	}
+
+	for _, src := range []interface{}{"fe80::1", []byte("fe80::1")} {
+		scanned := new(IPv6)
+		if err := scanned.Scan(src); err != nil || scanned.String() != "fe80::1" {
+			t.Errorf("Scan(%v) Expect: fe80::1  Result: %s %v", src, scanned, err)
+		}
+	}
+	for _, src := range []interface{}{"fe80::1::", 1, nil} {
+		if err := new(IPv6).Scan(src); err == nil {
+			t.Errorf("Scan(%v) Expect: error  Result: nil", src)
+		}
+	}
+}
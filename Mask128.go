@@ -50,6 +50,16 @@ func (m128 *Mask128) Cmp(other *Mask128) int {
 	return 0
 }
 
+// GobDecode implements gob.GobDecoder using the format of UnmarshalBinary.
+func (m128 *Mask128) GobDecode(data []byte) error {
+	return m128.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder using the format of MarshalBinary.
+func (m128 *Mask128) GobEncode() ([]byte, error) {
+	return m128.MarshalBinary()
+}
+
 // HostIdMask returns the internal uint64 mask for the host portion of the mask.
 func (m128 *Mask128) HostIdMask() uint64 {
 	return m128.hostIdMask
@@ -65,6 +75,12 @@ func (m128 *Mask128) Len() uint64 {
 	return m128.hostIdMask ^ F64 + 1 // bit flip the netmask and add 1
 }
 
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is a single byte
+// holding the prefix length.
+func (m128 *Mask128) MarshalBinary() ([]byte, error) {
+	return []byte{byte(m128.prefixLen)}, nil
+}
+
 // NetIdMask returns the internal uint64 mask for the network portion of the mask.
 func (m128 *Mask128) NetIdMask() uint64 {
 	return m128.netIdMask
@@ -80,6 +96,19 @@ func (m128 *Mask128) String() string {
 	return fmt.Sprintf("/%d", m128.prefixLen)
 }
 
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. See MarshalBinary.
+func (m128 *Mask128) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return fmt.Errorf("Mask128 must be exactly 1 byte. Received %d bytes.", len(data))
+	}
+	parsed, err := NewMask128(uint(data[0]))
+	if err != nil {
+		return err
+	}
+	*m128 = *parsed
+	return nil
+}
+
 
 // NON EXPORTED
 
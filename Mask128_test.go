@@ -79,6 +79,25 @@ func Test_NewMask128(t *testing.T) {
 	}
 }
 
+func Test_Mask128_PrefixLen(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect uint
+	}{
+		{"/0", 0},
+		{"/64", 64},
+		{"/65", 65},
+		{"/128", 128},
+	}
+
+	for _, c := range cases {
+		m128, _ := ParseMask128(c.given)
+		if m128.PrefixLen() != c.expect {
+			t.Errorf("%s.PrefixLen() Expect: %d  Result: %d", c.given, c.expect, m128.PrefixLen())
+		}
+	}
+}
+
 func Test_Mask128_String(t *testing.T) {
 	cases := []struct {
 		given  uint
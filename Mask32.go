@@ -71,6 +71,20 @@ func NewMask32(prefixLen uint) (*Mask32, error) {
 	return initMask32(prefixLen), nil
 }
 
+// Mask32ForHostCount returns the Mask32 of the smallest network (ie. longest prefix length)
+// capable of holding the given number of usable hosts. The network and broadcast addresses
+// are not considered usable for /30 and shorter networks. An error is returned if the
+// number of hosts exceeds the capacity of a /0.
+func Mask32ForHostCount(hosts uint32) (*Mask32, error) {
+	for prefixLen := 32; prefixLen >= 0; prefixLen -= 1 {
+		m32 := initMask32(uint(prefixLen))
+		if m32.usable() >= hosts {
+			return m32, nil
+		}
+	}
+	return nil, fmt.Errorf("Host count %d is too large for IPv4.", hosts)
+}
+
 /*
 Cmp compares equality with another Mask32. Return:
 	* 1 if this Mask32 is larger in capacity
@@ -96,6 +110,16 @@ func (m32 *Mask32) Extended() string {
 		m32.mask&0xff)
 }
 
+// GobDecode implements gob.GobDecoder using the format of UnmarshalBinary.
+func (m32 *Mask32) GobDecode(data []byte) error {
+	return m32.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder using the format of MarshalBinary.
+func (m32 *Mask32) GobEncode() ([]byte, error) {
+	return m32.MarshalBinary()
+}
+
 // Len returns the number of IP addresses in this network.
 // It will always return 0 for /0 networks.
 func (m32 *Mask32) Len() uint32 {
@@ -105,6 +129,12 @@ func (m32 *Mask32) Len() uint32 {
 	return m32.mask ^ F32 + 1 // bit flip the netmask and add 1
 }
 
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is a single byte
+// holding the prefix length.
+func (m32 *Mask32) MarshalBinary() ([]byte, error) {
+	return []byte{byte(m32.prefixLen)}, nil
+}
+
 // Mask returns the internal uint32 mask.
 func (m32 *Mask32) Mask() uint32 {
 	return m32.mask
@@ -121,6 +151,19 @@ func (m32 *Mask32) String() string {
 	return fmt.Sprintf("/%d", m32.prefixLen)
 }
 
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. See MarshalBinary.
+func (m32 *Mask32) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return fmt.Errorf("Mask32 must be exactly 1 byte. Received %d bytes.", len(data))
+	}
+	parsed, err := NewMask32(uint(data[0]))
+	if err != nil {
+		return err
+	}
+	*m32 = *parsed
+	return nil
+}
+
 
 // NON EXPORTED
 
@@ -131,3 +174,13 @@ func initMask32(prefixLen uint) *Mask32 {
 	m32.mask = F32 ^ (F32 >> uint32(prefixLen))
 	return m32
 }
+
+// usable returns the number of usable host addresses in a network of this size.
+// The network and broadcast addresses are excluded for /30 and shorter, both
+// addresses of a /31 are usable (rfc3021), and a /32 holds a single host.
+func (m32 *Mask32) usable() uint32 {
+	if m32.prefixLen >= 31 {
+		return 1 << (32 - m32.prefixLen)
+	}
+	return m32.mask ^ F32 - 1 // bit flip the netmask, add 1, and remove network/broadcast
+}
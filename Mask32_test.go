@@ -2,6 +2,7 @@ package netaddr
 
 import "testing"
 import "fmt"
+import "strings"
 
 func ExampleParseMask32() {
 	m32, _ := ParseMask32("/32")
@@ -21,6 +22,12 @@ func ExampleMask32_Extended() {
 	// Output: 255.255.255.0
 }
 
+func ExampleMask32_PrefixLen() {
+	net, _ := ParseIPv4Net("192.168.1.0 255.255.255.0")
+	fmt.Println(net.Netmask().PrefixLen())
+	// Output: 24
+}
+
 func Test_ParseMask32(t *testing.T) {
 	cases := []struct {
 		given  string
@@ -36,6 +43,12 @@ func Test_ParseMask32(t *testing.T) {
 		{"//32", 0, 0, true},
 		{"256.0.0.0", 0, 0, true},
 		{"255.248.255.0", 0, 0, true},
+		{"255.0.255.0", 0, 0, true},
+		{"255.255.0.255", 0, 0, true},
+		{"0.0.0.255", 0, 0, true},
+		{"127.255.255.255", 0, 0, true},
+		{"255.255.255.253", 0, 0, true},
+		{"255.255.255.254", 31, 0xfffffffe, false},
 		{"255", 0, 0, true},
 	}
 
@@ -61,6 +74,72 @@ func Test_ParseMask32(t *testing.T) {
 	}
 }
 
+// Test_ParseMask32_NonContiguous flips each bit of every valid netmask, which
+// must be rejected unless the result happens to be another valid netmask.
+func Test_ParseMask32_NonContiguous(t *testing.T) {
+	valid := make(map[uint32]bool)
+	for prefixLen := uint(0); prefixLen <= 32; prefixLen += 1 {
+		valid[initMask32(prefixLen).mask] = true
+	}
+
+	for mask := range valid {
+		for bit := uint(0); bit < 32; bit += 1 {
+			flipped := NewIPv4(mask ^ 1<<bit).String()
+			m32, err := ParseMask32(flipped)
+			if valid[mask^1<<bit] {
+				if err != nil {
+					t.Errorf("ParseMask32(%s) unexpected error: %s", flipped, err.Error())
+				}
+			} else if err == nil {
+				t.Errorf("ParseMask32(%s) Expect: error  Result: %s", flipped, m32)
+			} else if !strings.Contains(err.Error(), flipped) {
+				t.Errorf("ParseMask32(%s) Expect: error naming the mask  Result: %s", flipped, err.Error())
+			}
+		}
+	}
+
+	if _, err := ParseIPv4Net("10.0.0.0 255.0.255.0"); err == nil {
+		t.Errorf("ParseIPv4Net(10.0.0.0 255.0.255.0) expected error but none raised")
+	}
+}
+
+func Test_Mask32ForHostCount(t *testing.T) {
+	cases := []struct {
+		hosts     uint32
+		prefixLen uint
+		err       bool
+	}{
+		{0, 32, false},
+		{1, 32, false},
+		{2, 31, false},
+		{3, 29, false},
+		{254, 24, false},
+		{255, 23, false},
+		{500, 23, false},
+		{4294967294, 0, false},
+		{4294967295, 0, true},
+	}
+
+	for _, c := range cases {
+		m32, err := Mask32ForHostCount(c.hosts)
+		if err != nil {
+			if !c.err {
+				t.Errorf("Mask32ForHostCount(%d) unexpected error: %s", c.hosts, err.Error())
+			}
+			continue
+		}
+
+		if c.err {
+			t.Errorf("Mask32ForHostCount(%d) expected error but none raised", c.hosts)
+			continue
+		}
+
+		if m32.prefixLen != c.prefixLen {
+			t.Errorf("Mask32ForHostCount(%d) Expect: /%d  Result: %s", c.hosts, c.prefixLen, m32)
+		}
+	}
+}
+
 func Test_NewMask32(t *testing.T) {
 	cases := []struct {
 		given  uint
@@ -136,6 +215,25 @@ func Test_Mask32_Cmp(t *testing.T) {
 	}
 }
 
+func Test_Mask32_PrefixLen(t *testing.T) {
+	cases := []struct {
+		given  string
+		expect uint
+	}{
+		{"/0", 0},
+		{"/24", 24},
+		{"255.255.255.128", 25},
+		{"255.255.255.255", 32},
+	}
+
+	for _, c := range cases {
+		m32, _ := ParseMask32(c.given)
+		if m32.PrefixLen() != c.expect {
+			t.Errorf("%s.PrefixLen() Expect: %d  Result: %d", c.given, c.expect, m32.PrefixLen())
+		}
+	}
+}
+
 func Test_Mask32_String(t *testing.T) {
 	cases := []struct {
 		given  uint
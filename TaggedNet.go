@@ -0,0 +1,32 @@
+package netaddr
+
+// TaggedNet associates a value of any type, such as a label or IPAM record, with an IPv4Net.
+type TaggedNet[T any] struct {
+	Net   *IPv4Net
+	Value T
+}
+
+// TaggedNetList is a slice of TaggedNet types
+type TaggedNetList[T any] []*TaggedNet[T]
+
+// LongestMatch returns the entry of the list with the most specific (longest prefix)
+// network containing ip, or nil if no network contains it. If several entries hold
+// equal networks then the first of them is returned.
+func (list TaggedNetList[T]) LongestMatch(ip *IPv4) *TaggedNet[T] {
+	var match *TaggedNet[T]
+	for _, e := range list {
+		if e.Net.Contains(ip) && (match == nil || e.Net.m32.prefixLen > match.Net.m32.prefixLen) {
+			match = e
+		}
+	}
+	return match
+}
+
+// Nets returns the networks of the list as an IPv4NetList, in the same order.
+func (list TaggedNetList[T]) Nets() IPv4NetList {
+	nets := make(IPv4NetList, len(list))
+	for i, e := range list {
+		nets[i] = e.Net
+	}
+	return nets
+}
@@ -0,0 +1,70 @@
+package netaddr
+
+import "testing"
+import "fmt"
+
+func ExampleTaggedNetList_LongestMatch() {
+	list := TaggedNetList[string]{
+		{MustParseIPv4Net("10.0.0.0/8"), "corporate"},
+		{MustParseIPv4Net("10.1.0.0/16"), "datacenter"},
+	}
+	match := list.LongestMatch(MustParseIPv4("10.1.2.3"))
+	fmt.Println(match.Net, match.Value)
+	// Output: 10.1.0.0/16 datacenter
+}
+
+func Test_TaggedNetList_LongestMatch(t *testing.T) {
+	type vlan struct {
+		id   int
+		name string
+	}
+	list := TaggedNetList[vlan]{
+		{MustParseIPv4Net("10.0.0.0/8"), vlan{1, "default"}},
+		{MustParseIPv4Net("10.0.0.0/24"), vlan{10, "servers"}},
+		{MustParseIPv4Net("10.0.0.128/25"), vlan{20, "storage"}},
+		{MustParseIPv4Net("10.0.0.0/24"), vlan{30, "duplicate"}},
+		{MustParseIPv4Net("192.168.0.0/16"), vlan{40, "lab"}},
+	}
+	cases := []struct {
+		ip     string
+		expect int // vlan id or 0 for no match
+	}{
+		{"10.0.0.200", 20},
+		{"10.0.0.1", 10}, // first of equal networks
+		{"10.9.9.9", 1},
+		{"192.168.1.1", 40},
+		{"172.16.0.1", 0},
+	}
+
+	for _, c := range cases {
+		match := list.LongestMatch(MustParseIPv4(c.ip))
+		if match == nil {
+			if c.expect != 0 {
+				t.Errorf("LongestMatch(%s) Expect: vlan %d  Result: nil", c.ip, c.expect)
+			}
+		} else if match.Value.id != c.expect {
+			t.Errorf("LongestMatch(%s) Expect: vlan %d  Result: vlan %d (%s)", c.ip, c.expect, match.Value.id, match.Net)
+		}
+	}
+
+	if match := list.LongestMatch(nil); match != nil {
+		t.Errorf("LongestMatch(nil) Expect: nil  Result: %s", match.Net)
+	}
+	if match := (TaggedNetList[vlan]{}).LongestMatch(MustParseIPv4("10.0.0.1")); match != nil {
+		t.Errorf("LongestMatch() on an empty list Expect: nil  Result: %s", match.Net)
+	}
+}
+
+func Test_TaggedNetList_Nets(t *testing.T) {
+	list := TaggedNetList[int]{
+		{MustParseIPv4Net("10.0.1.0/24"), 1},
+		{MustParseIPv4Net("10.0.0.0/24"), 2},
+	}
+	expect := "[10.0.1.0/24 10.0.0.0/24]"
+	if nets := list.Nets(); fmt.Sprint(nets) != expect {
+		t.Errorf("Nets() Expect: %s  Result: %v", expect, nets)
+	}
+	if summ := list.Nets().Summ(); fmt.Sprint(summ) != "[10.0.0.0/23]" {
+		t.Errorf("Nets().Summ() Expect: [10.0.0.0/23]  Result: %v", summ)
+	}
+}
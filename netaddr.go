@@ -4,6 +4,9 @@ netaddr is a Go library for performing calculations on IPv4 and IPv6 subnets. Th
 package netaddr
 
 import (
+	"encoding/gob"
+	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -16,16 +19,55 @@ const (
 	F64 uint64 = 0xffffffffffffffff
 )
 
+// IP is implemented by both *IPv4 and *IPv6. See ParseIP.
 type IP interface{
 	String() string
 	Version() uint
 }
 
+// IPNet is implemented by both *IPv4Net and *IPv6Net. See ParseIPNet.
+// It provides the operations which are common to both, using types wide
+// enough for either address family.
 type IPNet interface{
+	ContainsIP(ip IP) bool
+	LenBig() *big.Int
+	PrefixLen() uint
 	String() string
 	Version() uint
 }
 
+// Relationship describes how a network relates to another network.
+type Relationship int
+
+const (
+	// the networks do not overlap
+	Unrelated Relationship = iota
+
+	// the networks are identical
+	Equal
+
+	// the network contains the other
+	Supernet
+
+	// the network is contained by the other
+	Subnet
+)
+
+// String returns the name of the Relationship.
+func (rel Relationship) String() string {
+	switch rel {
+	case Unrelated:
+		return "Unrelated"
+	case Equal:
+		return "Equal"
+	case Supernet:
+		return "Supernet"
+	case Subnet:
+		return "Subnet"
+	}
+	return "Relationship(" + strconv.Itoa(int(rel)) + ")"
+}
+
 
 
 // IPv4PrefixLen returns the prefix length needed to hold the
@@ -42,20 +84,53 @@ func IPv4PrefixLen(size uint) uint {
 	return prefix
 }
 
-// ParseIP parses a string into an IP
+// ParseIP parses a string into an IP, which will be an *IPv6 if the string contains
+// a ':' and an *IPv4 otherwise. The returned IP is nil if the string is not valid.
 func ParseIP(ip string) (IP,error){
 	if strings.Contains(ip, ":"){
-		return ParseIPv6(ip)
+		ip6, err := ParseIPv6(ip)
+		if err != nil {
+			return nil, err
+		}
+		return ip6, nil
+	}
+	ip4, err := ParseIPv4(ip)
+	if err != nil {
+		return nil, err
 	}
-	return ParseIPv4(ip)
+	return ip4, nil
 }
 
-// ParseIPNet parses a string into an IPNet
+// ParseIPNet parses a string into an IPNet, which will be an *IPv6Net if the string
+// contains a ':' and an *IPv4Net otherwise. The returned IPNet is nil if the string is not valid.
 func ParseIPNet(net string) (IPNet,error){
 	if strings.Contains(net, ":"){
-		return ParseIPv6Net(net)
+		net6, err := ParseIPv6Net(net)
+		if err != nil {
+			return nil, err
+		}
+		return net6, nil
+	}
+	net4, err := ParseIPv4Net(net)
+	if err != nil {
+		return nil, err
 	}
-	return ParseIPv4Net(net)
+	return net4, nil
+}
+
+/*
+RegisterGob registers the address, network and netmask types of this package with
+encoding/gob. This is only required when values are encoded through an interface type
+(eg. IP, IPNet, or interface{}); concrete types may be encoded without registration.
+It is safe to call more than once.
+*/
+func RegisterGob() {
+	gob.Register(&IPv4{})
+	gob.Register(&IPv6{})
+	gob.Register(&IPv4Net{})
+	gob.Register(&IPv6Net{})
+	gob.Register(&Mask32{})
+	gob.Register(&Mask128{})
 }
 
 // NON EXPORTED
@@ -69,6 +144,76 @@ func cleanupEUI(addr string) string {
 	return addr
 }
 
+// formatDirective rebuilds the formatting directive (eg. "%-15s") described by a fmt.State and verb.
+func formatDirective(f fmt.State, verb rune) string {
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+	if width, ok := f.Width(); ok {
+		directive += strconv.Itoa(width)
+	}
+	if prec, ok := f.Precision(); ok {
+		directive += "." + strconv.Itoa(prec)
+	}
+	return directive + string(verb)
+}
+
+// parseDecimal parses a string of decimal digits which may not exceed max.
+// It returns false if the string is empty, contains non-digits, or exceeds max.
+func parseDecimal(s string, max uint32) (uint32, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	var u32 uint32
+	for i := 0; i < len(s); i += 1 {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		u32 = u32*10 + uint32(s[i]-'0')
+		if u32 > max {
+			return 0, false
+		}
+	}
+	return u32, true
+}
+
+// parseDottedQuad parses an IPv4 address in dotted-quad format to a uint32 without allocating.
+func parseDottedQuad(s string) (uint32, bool) {
+	var u32 uint32
+	for i := 0; i < 4; i += 1 {
+		end := strings.IndexByte(s, '.')
+		if i == 3 {
+			end = len(s)
+		} else if end == -1 {
+			return 0, false
+		}
+		u8, ok := parseDecimal(s[:end], 0xff)
+		if !ok {
+			return 0, false
+		}
+		u32 = u32<<8 | u8
+		if i != 3 {
+			s = s[end+1:]
+		}
+	}
+	return u32, true
+}
+
+// scanString returns the string held by src, which may be a string or []byte as provided to
+// sql.Scanner implementations. name is the type being scanned into, for use in errors.
+func scanString(src interface{}, name string) (string, error) {
+	switch v := src.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	return "", fmt.Errorf("Cannot scan a value of type %T into %s.", src, name)
+}
+
 // u8SlicetoU32 converts a slice of 4 strings representing uint8 numbers (base 10) to a uint32.
 func u8SlicetoU32(group []string) (uint32, error) {
 	var g uint64 = 4
@@ -1,6 +1,8 @@
 package netaddr
 
 import "testing"
+import "bytes"
+import "encoding/gob"
 import "fmt"
 
 func ExampleIPv4PrefixLen() {
@@ -53,3 +55,171 @@ func Test_IPv4PrefixLen(t *testing.T) {
 		}
 	}
 }
+
+func Test_ParseIP(t *testing.T) {
+	cases := []struct {
+		given   string
+		version uint
+		expect  string
+	}{
+		{"10.0.0.1", 4, "10.0.0.1"},
+		{"fe80::1", 6, "fe80::1"},
+		{"::ffff:10.0.0.1", 6, "::ffff:10.0.0.1"},
+		{"10.0.0.256", 0, ""},
+		{"fe80:::1", 0, ""},
+		{"", 0, ""},
+	}
+
+	for _, c := range cases {
+		ip, err := ParseIP(c.given)
+		if c.version == 0 {
+			if err == nil || ip != nil {
+				t.Errorf("ParseIP(%s) Expect: nil and an error  Result: %v, %v", c.given, ip, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseIP(%s) unexpected error: %s", c.given, err)
+		} else if ip.Version() != c.version || ip.String() != c.expect {
+			t.Errorf("ParseIP(%s) Expect: %s (v%d)  Result: %s (v%d)", c.given, c.expect, c.version, ip, ip.Version())
+		}
+	}
+}
+
+func Test_ParseIPNet(t *testing.T) {
+	cases := []struct {
+		given   string
+		version uint
+		expect  string
+	}{
+		{"10.0.0.0/24", 4, "10.0.0.0/24"},
+		{"10.0.0.1", 4, "10.0.0.1/32"},
+		{"fec0::/10", 6, "fec0::/10"},
+		{"10.0.0.0/33", 0, ""},
+		{"fec0::/129", 0, ""},
+		{"bogus", 0, ""},
+	}
+
+	for _, c := range cases {
+		net, err := ParseIPNet(c.given)
+		if c.version == 0 {
+			if err == nil || net != nil {
+				t.Errorf("ParseIPNet(%s) Expect: nil and an error  Result: %v, %v", c.given, net, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseIPNet(%s) unexpected error: %s", c.given, err)
+		} else if net.Version() != c.version || net.String() != c.expect {
+			t.Errorf("ParseIPNet(%s) Expect: %s (v%d)  Result: %s (v%d)", c.given, c.expect, c.version, net, net.Version())
+		}
+	}
+}
+
+func Test_IPNet(t *testing.T) {
+	cases := []struct {
+		net       string
+		ip        string
+		contains  bool
+		len       string
+		prefixLen uint
+	}{
+		{"10.0.0.0/24", "10.0.0.1", true, "256", 24},
+		{"10.0.0.0/24", "10.0.1.1", false, "256", 24},
+		{"10.0.0.0/24", "::ffff:10.0.0.1", false, "256", 24}, // different family
+		{"0.0.0.0/0", "255.255.255.255", true, "4294967296", 0},
+		{"1::/64", "1::1", true, "18446744073709551616", 64},
+		{"1::/64", "10.0.0.1", false, "18446744073709551616", 64},
+		{"::/0", "ffff::", true, "340282366920938463463374607431768211456", 0},
+		{"1::1/128", "1::1", true, "1", 128},
+	}
+
+	for _, c := range cases {
+		net, _ := ParseIPNet(c.net)
+		ip, _ := ParseIP(c.ip)
+		if contains := net.ContainsIP(ip); contains != c.contains {
+			t.Errorf("%s.ContainsIP(%s) Expect: %v  Result: %v", c.net, c.ip, c.contains, contains)
+		}
+		if l := net.LenBig(); l.String() != c.len {
+			t.Errorf("%s.LenBig() Expect: %s  Result: %s", c.net, c.len, l)
+		}
+		if prefixLen := net.PrefixLen(); prefixLen != c.prefixLen {
+			t.Errorf("%s.PrefixLen() Expect: %d  Result: %d", c.net, c.prefixLen, prefixLen)
+		}
+	}
+
+	if MustParseIPv4Net("10.0.0.0/8").ContainsIP(nil) {
+		t.Errorf("10.0.0.0/8.ContainsIP(nil) Expect: false  Result: true")
+	}
+}
+
+func Test_Gob(t *testing.T) {
+	RegisterGob()
+	RegisterGob() // safe to call again
+
+	ip4, _ := ParseIPv4("192.168.1.1")
+	ip6, _ := ParseIPv6("fe80::1%eth0")
+	net4, _ := ParseIPv4Net("10.0.0.0/8")
+	net6, _ := ParseIPv6Net("2001:db8::/32")
+	m32, _ := NewMask32(24)
+	m128, _ := NewMask128(64)
+	given := []interface{}{ip4, ip6, net4, net6, m32, m128}
+
+	// encoded through an interface type
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(given); err != nil {
+		t.Fatalf("gob.Encode(%v) unexpected error: %s", given, err)
+	}
+	var decoded []interface{}
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatalf("gob.Decode() unexpected error: %s", err)
+	}
+	if fmt.Sprint(decoded) != fmt.Sprint(given) {
+		t.Errorf("gob round trip Expect: %v  Result: %v", given, decoded)
+	}
+
+	// concrete types within a struct
+	type cache struct {
+		Nets IPv4NetList
+		Addr *IPv6
+	}
+	buf.Reset()
+	if err := gob.NewEncoder(&buf).Encode(cache{IPv4NetList{net4}, ip6}); err != nil {
+		t.Fatalf("gob.Encode() unexpected error: %s", err)
+	}
+	var c cache
+	if err := gob.NewDecoder(&buf).Decode(&c); err != nil {
+		t.Fatalf("gob.Decode() unexpected error: %s", err)
+	}
+	if fmt.Sprint(c.Nets, c.Addr) != fmt.Sprint(IPv4NetList{net4}, ip6) {
+		t.Errorf("gob round trip Expect: %v %s  Result: %v %s", IPv4NetList{net4}, ip6, c.Nets, c.Addr)
+	}
+	if cmp, _ := c.Nets[0].Cmp(net4); cmp != 0 || c.Nets[0].Len() != net4.Len() {
+		t.Errorf("gob round trip Expect: %s  Result: %s (len %d)", net4, c.Nets[0], c.Nets[0].Len())
+	}
+}
+
+func Test_UnmarshalBinary_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		u    interface{ UnmarshalBinary([]byte) error }
+		data []byte
+	}{
+		{"IPv4", new(IPv4), []byte{1, 2, 3}},
+		{"IPv6", new(IPv6), make([]byte, 15)},
+		{"Mask32", new(Mask32), []byte{33}},
+		{"Mask32", new(Mask32), []byte{}},
+		{"Mask128", new(Mask128), []byte{129}},
+		{"Mask128", new(Mask128), []byte{1, 2}},
+		{"IPv4Net", new(IPv4Net), []byte{10, 0, 0, 0}},
+		{"IPv4Net", new(IPv4Net), []byte{10, 0, 0, 0, 33}},
+		{"IPv6Net", new(IPv6Net), make([]byte, 16)},
+		{"IPv6Net", new(IPv6Net), append(make([]byte, 16), 129)},
+	}
+
+	for _, c := range cases {
+		if err := c.u.UnmarshalBinary(c.data); err == nil {
+			t.Errorf("%s.UnmarshalBinary(%v) Expect: error  Result: nil", c.name, c.data)
+		}
+	}
+}